@@ -4,29 +4,58 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"mor80/service-reviewer/internal/config"
 	"mor80/service-reviewer/internal/db/postgres"
+	authhandler "mor80/service-reviewer/internal/handlers/auth"
 	prhandler "mor80/service-reviewer/internal/handlers/pullrequest"
+	"mor80/service-reviewer/internal/handlers/status"
 	teamhandler "mor80/service-reviewer/internal/handlers/team"
 	userhandler "mor80/service-reviewer/internal/handlers/user"
+	webhookhandler "mor80/service-reviewer/internal/handlers/webhook"
 	"mor80/service-reviewer/internal/httpserver"
+	"mor80/service-reviewer/internal/idempotency"
+	"mor80/service-reviewer/internal/metrics"
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/ratelimit"
+	absencerepo "mor80/service-reviewer/internal/repository/postgres/absence"
+	apitokenrepo "mor80/service-reviewer/internal/repository/postgres/apitoken"
+	eventrepo "mor80/service-reviewer/internal/repository/postgres/event"
+	idempotencyrepo "mor80/service-reviewer/internal/repository/postgres/idempotency"
+	outboxrepo "mor80/service-reviewer/internal/repository/postgres/outbox"
 	prrepo "mor80/service-reviewer/internal/repository/postgres/pullrequest"
+	ratelimitrepo "mor80/service-reviewer/internal/repository/postgres/ratelimit"
 	teamrepo "mor80/service-reviewer/internal/repository/postgres/team"
 	userrepo "mor80/service-reviewer/internal/repository/postgres/user"
+	webhookrepo "mor80/service-reviewer/internal/repository/postgres/webhook"
+	"mor80/service-reviewer/internal/scheduler"
+	"mor80/service-reviewer/internal/service"
+	accessservice "mor80/service-reviewer/internal/service/access"
+	authservice "mor80/service-reviewer/internal/service/auth"
+	outboxservice "mor80/service-reviewer/internal/service/outbox"
 	prservice "mor80/service-reviewer/internal/service/pullrequest"
 	teamservice "mor80/service-reviewer/internal/service/team"
 	userservice "mor80/service-reviewer/internal/service/user"
+	webhookservice "mor80/service-reviewer/internal/service/webhook"
+	"mor80/service-reviewer/internal/tracing"
+	"mor80/service-reviewer/migrations"
 	"mor80/service-reviewer/pkg/logger"
 )
 
 type App struct {
-	config *config.Config
-	logger *slog.Logger
-	db     *pgxpool.Pool
-	server *httpserver.Server
+	config     *config.Config
+	logger     *slog.Logger
+	db         *pgxpool.Pool
+	server     *httpserver.Server
+	probe      *status.Probe
+	dispatcher *webhookservice.Dispatcher
+	relay      *outboxservice.Relay
+	scheduler  *scheduler.Scheduler
+	tracing    func(context.Context) error
 }
 
 func New(ctx context.Context, configPath string) (*App, error) {
@@ -37,45 +66,406 @@ func New(ctx context.Context, configPath string) (*App, error) {
 
 	log := logger.New(logger.EnvString(cfg.App.Env))
 
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing, cfg.App.Name)
+	if err != nil {
+		return nil, fmt.Errorf("app: init tracing: %w", err)
+	}
+
 	pool, err := postgres.NewPool(ctx, cfg.Postgres)
 	if err != nil {
 		return nil, fmt.Errorf("app: init postgres: %w", err)
 	}
 
+	txManager := service.WithTxHooks(postgres.NewTxManager(pool))
+	appMetrics := metrics.New(pool)
 	userRepo := userrepo.New(pool)
 	teamRepo := teamrepo.New(pool)
 	pullRepo := prrepo.New(pool)
+	eventRepo := eventrepo.New(pool)
+	webhookRepo := webhookrepo.New(pool)
+	outboxRepo := outboxrepo.New(pool)
+	absenceRepo := absencerepo.New(pool)
+	tokenRepo := apitokenrepo.New(pool)
+	bucketRepo := ratelimitrepo.New(pool)
+	idempotencyRepo := idempotencyrepo.New(pool)
+
+	selector, err := prservice.NewSelector(selectorConfig(cfg.Reviewers), pullRepo, nil)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init reviewer selector: %w", err)
+	}
 
-	userSvc := userservice.New(userRepo, pullRepo)
-	pullSvc := prservice.New(pullRepo, userRepo, nil)
-	teamSvc := teamservice.New(teamRepo, userRepo, pullRepo, pullSvc)
+	access := accessservice.New(teamRepo)
+	pullSvc := prservice.New(pullRepo, userRepo, teamRepo, eventRepo, absenceRepo, selector, cfg.Reviewers.RequiredApprovals, txManager, appMetrics, access)
+	userSvc := userservice.New(userRepo, pullRepo, eventRepo, absenceRepo, pullSvc, txManager, appMetrics, access)
+	teamSvc := teamservice.New(teamRepo, userRepo, pullRepo, pullSvc, txManager, appMetrics, access)
+	webhookSvc := webhookservice.New(webhookRepo)
+	authSvc := authservice.New(tokenRepo, userRepo, cfg.Auth.AdminTokens)
 
 	userHandler := userhandler.New(userSvc)
 	teamHandler := teamhandler.New(teamSvc)
 	pullHandler := prhandler.New(pullSvc)
+	webhookHandler := webhookhandler.New(webhookSvc)
+	authHandler := authhandler.New(authSvc)
+
+	authenticate, err := authMiddleware(ctx, cfg.Auth, authSvc, userRepo, log)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init auth: %w", err)
+	}
+
+	realIP, err := httpserver.RealIP(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init http: %w", err)
+	}
+
+	addressLimit, rateLimit, err := rateLimitMiddleware(cfg.RateLimit, bucketRepo, log)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init rate limit: %w", err)
+	}
+
+	replayer := idempotency.New(idempotencyRepo, idempotency.Config{
+		TTL:         cfg.Idempotency.TTL,
+		Lease:       cfg.Idempotency.Lease,
+		MaxBodySize: cfg.Idempotency.MaxBodySize,
+	}, log)
 
-	router := httpserver.NewRouter(log, userHandler, teamHandler, pullHandler)
+	schemaVersion, err := migrations.Latest()
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: read migrations: %w", err)
+	}
+
+	probe := status.NewProbe(
+		status.Check{Name: "postgres", Check: pool.Ping},
+		status.Check{Name: "migrations", Check: migrationCheck(pool, schemaVersion)},
+	)
+
+	router := httpserver.NewRouter(log, userHandler, teamHandler, pullHandler, webhookHandler, authHandler, httpserver.Middlewares{
+		RealIP:       realIP,
+		AddressLimit: addressLimit,
+		Authenticate: authenticate,
+		RateLimit:    rateLimit,
+		Idempotent:   replayer.Middleware,
+	}, appMetrics, probe)
 	server := httpserver.New(cfg.HTTP, log, router)
 
+	dispatcher := webhookservice.NewDispatcher(webhookRepo, nil, log, webhookservice.DispatcherConfig{
+		PollInterval: cfg.Webhooks.PollInterval,
+		BatchSize:    cfg.Webhooks.BatchSize,
+		MaxAttempts:  cfg.Webhooks.MaxAttempts,
+		BaseBackoff:  cfg.Webhooks.BaseBackoff,
+		MaxBackoff:   cfg.Webhooks.MaxBackoff,
+		Timeout:      cfg.Webhooks.Timeout,
+	})
+
+	sinks, err := outboxSinks(cfg.Outbox, log)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init outbox sinks: %w", err)
+	}
+
+	relay := outboxservice.NewRelay(outboxRepo, sinks, log, outboxservice.RelayConfig{
+		PollInterval: cfg.Outbox.PollInterval,
+		BatchSize:    cfg.Outbox.BatchSize,
+		RetryDelay:   cfg.Outbox.RetryDelay,
+		Lease:        cfg.Outbox.RetryDelay + cfg.Outbox.Webhook.Timeout,
+	})
+
+	jobs := scheduler.New(postgres.NewAdvisoryLocker(pool), postgres.NewJobRuns(pool), log)
+	jobsCfg := cfg.Jobs
+	var bucketRefill time.Duration
+	if cfg.RateLimit.Backend == "postgres" {
+		bucketRefill = rateLimitConfig(cfg.RateLimit).RefillTime()
+	} else {
+		jobsCfg.RateLimitCleanup.Schedule = ""
+	}
+
+	absences := userservice.NewAbsenceWatcher(userSvc, log, cfg.Absences.CheckInterval)
+	if err := registerJobs(jobs, jobsCfg, pullSvc, absences, bucketRepo, bucketRefill, idempotencyRepo, log); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("app: init jobs: %w", err)
+	}
+
 	return &App{
-		config: cfg,
-		logger: log,
-		db:     pool,
-		server: server,
+		config:     cfg,
+		logger:     log,
+		db:         pool,
+		server:     server,
+		probe:      probe,
+		dispatcher: dispatcher,
+		relay:      relay,
+		scheduler:  jobs,
+		tracing:    shutdownTracing,
+	}, nil
+}
+
+func authMiddleware(
+	ctx context.Context,
+	cfg config.Auth,
+	authSvc *authservice.AuthService,
+	userRepo *userrepo.UserRepository,
+	log *slog.Logger,
+) (func(http.Handler) http.Handler, error) {
+	switch cfg.Mode {
+	case "none":
+		return authhandler.Anonymous, nil
+	case "token":
+		if len(cfg.AdminTokens) == 0 {
+			log.Warn("auth: no admin tokens configured, API tokens cannot be issued")
+		}
+		return authhandler.Authenticate(authSvc), nil
+	case "jwt":
+		keys, err := authservice.NewJWKS(ctx, cfg.JWT.JWKSFile, cfg.JWT.JWKSURL, nil, cfg.JWT.RefreshInterval)
+		if err != nil {
+			return nil, err
+		}
+		jwtCfg, err := jwtConfig(cfg.JWT)
+		if err != nil {
+			return nil, err
+		}
+		return authhandler.Authenticate(authservice.NewJWTAuthenticator(keys, userRepo, jwtCfg)), nil
+	default:
+		return nil, fmt.Errorf("unknown auth mode: %s", cfg.Mode)
+	}
+}
+
+// rateLimitMiddleware returns the per address limit, applied before authentication, and the per
+// caller one, applied after it.
+func rateLimitMiddleware(cfg config.RateLimit, buckets *ratelimitrepo.BucketRepository, log *slog.Logger) (addressLimit, clientLimit func(http.Handler) http.Handler, err error) {
+	var store ratelimit.Store
+
+	switch cfg.Backend {
+	case "none":
+		pass := func(next http.Handler) http.Handler { return next }
+		return pass, pass, nil
+	case "memory":
+		store = ratelimit.NewMemoryStore()
+	case "postgres":
+		store = buckets
+	default:
+		return nil, nil, fmt.Errorf("unknown rate limit backend: %s", cfg.Backend)
+	}
+
+	limiter, err := ratelimit.New(store, rateLimitConfig(cfg), log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return limiter.AddressMiddleware, limiter.Middleware, nil
+}
+
+func rateLimitConfig(cfg config.RateLimit) ratelimit.Config {
+	endpoints := make(map[string]ratelimit.Rule, len(cfg.Endpoints))
+	for path, rule := range cfg.Endpoints {
+		endpoints[path] = ratelimit.Rule{Rate: rule.Rate, Burst: rule.Burst}
+	}
+
+	return ratelimit.Config{
+		Default:   ratelimit.Rule{Rate: cfg.Rate, Burst: cfg.Burst},
+		Endpoints: endpoints,
+		Address:   ratelimit.Rule{Rate: cfg.Address.Rate, Burst: cfg.Address.Burst},
+	}
+}
+
+func jwtConfig(cfg config.AuthJWT) (authservice.JWTConfig, error) {
+	roleMapping := make(map[string]model.Role, len(cfg.RoleMapping))
+	for value, role := range cfg.RoleMapping {
+		if !model.Role(role).Valid() {
+			return authservice.JWTConfig{}, fmt.Errorf("jwt role mapping %s: unknown role %s", value, role)
+		}
+		roleMapping[value] = model.Role(role)
+	}
+
+	if cfg.DefaultRole != "" && !model.Role(cfg.DefaultRole).Valid() {
+		return authservice.JWTConfig{}, fmt.Errorf("jwt default role: unknown role %s", cfg.DefaultRole)
+	}
+
+	return authservice.JWTConfig{
+		Issuer:      cfg.Issuer,
+		Audience:    cfg.Audience,
+		UserClaim:   cfg.UserClaim,
+		RoleClaim:   cfg.RoleClaim,
+		RoleMapping: roleMapping,
+		DefaultRole: model.Role(cfg.DefaultRole),
+		Leeway:      cfg.Leeway,
 	}, nil
 }
 
+// migrationCheck fails until the database schema is at least the newest embedded migration.
+func migrationCheck(pool *pgxpool.Pool, expected int64) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		version, err := postgres.MigrationVersion(ctx, pool)
+		if err != nil {
+			return err
+		}
+
+		if version < expected {
+			return fmt.Errorf("schema version %d, expected %d", version, expected)
+		}
+
+		return nil
+	}
+}
+
+func outboxSinks(cfg config.Outbox, log *slog.Logger) ([]outboxservice.Sink, error) {
+	sinks := make([]outboxservice.Sink, 0, len(cfg.Sinks))
+
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, outboxservice.NewLogSink(log))
+		case "file":
+			if cfg.File.Path == "" {
+				return nil, fmt.Errorf("file sink: path is not set")
+			}
+			sinks = append(sinks, outboxservice.NewFileSink(cfg.File.Path))
+		case "webhook":
+			if cfg.Webhook.URL == "" {
+				return nil, fmt.Errorf("webhook sink: url is not set")
+			}
+			client := &http.Client{Timeout: cfg.Webhook.Timeout}
+			sinks = append(sinks, outboxservice.NewWebhookSink(cfg.Webhook.URL, cfg.Webhook.Secret, client))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink: %s", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+func registerJobs(
+	s *scheduler.Scheduler,
+	cfg config.Jobs,
+	pullSvc *prservice.PullRequestService,
+	absences *userservice.AbsenceWatcher,
+	buckets *ratelimitrepo.BucketRepository,
+	bucketRefill time.Duration,
+	idempotencyKeys *idempotencyrepo.IdempotencyRepository,
+	log *slog.Logger,
+) error {
+	jobs := []scheduler.Job{
+		{
+			Name:     "stale_reviews",
+			Schedule: cfg.StaleReviews.Schedule,
+			Run: func(ctx context.Context) error {
+				escalated, err := pullSvc.EscalateStaleReviews(ctx, cfg.StaleReviews.After)
+				if escalated > 0 {
+					log.Info("escalated stale reviews", "count", escalated)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "absences",
+			Schedule: cfg.Absences.Schedule,
+			Run:      absences.RunOnce,
+		},
+		{
+			Name:     "review_sla",
+			Schedule: cfg.ReviewSLA.Schedule,
+			Run: func(ctx context.Context) error {
+				reassigned, err := pullSvc.ReassignOverdueReviews(ctx, cfg.ReviewSLA.ReassignFactor)
+				if reassigned > 0 {
+					log.Info("reassigned overdue reviews", "count", reassigned)
+				}
+				return err
+			},
+		},
+		{
+			Name:     "stats_snapshot",
+			Schedule: cfg.StatsSnapshot.Schedule,
+			Run: func(ctx context.Context) error {
+				_, err := pullSvc.SnapshotAssignmentStats(ctx)
+				return err
+			},
+		},
+		{
+			Name:     "rate_limit_cleanup",
+			Schedule: cfg.RateLimitCleanup.Schedule,
+			Run: func(ctx context.Context) error {
+				// a bucket idle for the refill time is full, dropping it changes nothing
+				_, err := buckets.DeleteIdle(ctx, time.Now().Add(-bucketRefill))
+				return err
+			},
+		},
+		{
+			Name:     "idempotency_cleanup",
+			Schedule: cfg.IdempotencyCleanup.Schedule,
+			Run: func(ctx context.Context) error {
+				_, err := idempotencyKeys.DeleteExpired(ctx)
+				return err
+			},
+		},
+	}
+
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func selectorConfig(cfg config.Reviewers) prservice.SelectorConfig {
+	teamStrategies := make(map[string]prservice.Strategy, len(cfg.TeamStrategies))
+	for teamName, strategy := range cfg.TeamStrategies {
+		teamStrategies[teamName] = prservice.Strategy(strategy)
+	}
+
+	return prservice.SelectorConfig{
+		Strategy:       prservice.Strategy(cfg.Strategy),
+		TeamStrategies: teamStrategies,
+		Weights:        cfg.Weights,
+	}
+}
+
 func (a *App) Run() error {
 	addr := fmt.Sprintf("%s:%d", a.config.HTTP.Host, a.config.HTTP.Port)
 	a.logger.Info("service-reviewer starting", "env", a.config.App.Env, "addr", addr)
 
+	a.dispatcher.Start(context.Background())
+	a.relay.Start(context.Background())
+	a.scheduler.Start(context.Background())
+	a.probe.SetReady(true)
+
 	return a.server.Start()
 }
 
 func (a *App) Shutdown(ctx context.Context) {
+	a.probe.SetReady(false)
+
+	if delay := a.config.HTTP.DrainDelay; delay > 0 {
+		a.logger.Info("draining traffic before shutdown", "delay", delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
 	if err := a.server.Shutdown(ctx); err != nil {
 		a.logger.Error("http server shutdown error", "err", err)
 	}
 
+	if err := a.dispatcher.Stop(ctx); err != nil {
+		a.logger.Error("webhook dispatcher shutdown error", "err", err)
+	}
+
+	if err := a.relay.Stop(ctx); err != nil {
+		a.logger.Error("outbox relay shutdown error", "err", err)
+	}
+
+	if err := a.scheduler.Stop(ctx); err != nil {
+		a.logger.Error("scheduler shutdown error", "err", err)
+	}
+
 	a.db.Close()
+
+	if err := a.tracing(ctx); err != nil {
+		a.logger.Error("tracing shutdown error", "err", err)
+	}
 }
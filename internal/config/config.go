@@ -17,9 +17,18 @@ import (
 
 type (
 	Config struct {
-		App      App      `koanf:"app"`
-		HTTP     HTTP     `koanf:"http"`
-		Postgres Postgres `koanf:"postgres"`
+		App         App         `koanf:"app"`
+		HTTP        HTTP        `koanf:"http"`
+		Postgres    Postgres    `koanf:"postgres"`
+		Reviewers   Reviewers   `koanf:"reviewers"`
+		Webhooks    Webhooks    `koanf:"webhooks"`
+		Outbox      Outbox      `koanf:"outbox"`
+		Absences    Absences    `koanf:"absences"`
+		Jobs        Jobs        `koanf:"jobs"`
+		Tracing     Tracing     `koanf:"tracing"`
+		Auth        Auth        `koanf:"auth"`
+		RateLimit   RateLimit   `koanf:"rate_limit"`
+		Idempotency Idempotency `koanf:"idempotency"`
 	}
 
 	App struct {
@@ -33,6 +42,10 @@ type (
 		Port         int           `koanf:"port"`
 		ReadTimeout  time.Duration `koanf:"read_timeout"`
 		WriteTimeout time.Duration `koanf:"write_timeout"`
+		// DrainDelay is how long the service reports not ready before the server stops on shutdown.
+		DrainDelay time.Duration `koanf:"drain_delay"`
+		// TrustedProxies are the IPs or CIDRs whose X-Forwarded-For and X-Real-IP headers are believed.
+		TrustedProxies []string `koanf:"trusted_proxies"`
 	}
 
 	Postgres struct {
@@ -43,6 +56,130 @@ type (
 		DBName   string `koanf:"db_name"`
 		DSN      string `koanf:"dsn"`
 	}
+
+	Reviewers struct {
+		Strategy          string            `koanf:"strategy"`
+		TeamStrategies    map[string]string `koanf:"team_strategies"`
+		Weights           map[string]int    `koanf:"weights"`
+		RequiredApprovals int               `koanf:"required_approvals"`
+	}
+
+	Webhooks struct {
+		PollInterval time.Duration `koanf:"poll_interval"`
+		BatchSize    int           `koanf:"batch_size"`
+		MaxAttempts  int           `koanf:"max_attempts"`
+		BaseBackoff  time.Duration `koanf:"base_backoff"`
+		MaxBackoff   time.Duration `koanf:"max_backoff"`
+		Timeout      time.Duration `koanf:"timeout"`
+	}
+
+	Outbox struct {
+		PollInterval time.Duration `koanf:"poll_interval"`
+		BatchSize    int           `koanf:"batch_size"`
+		RetryDelay   time.Duration `koanf:"retry_delay"`
+		Sinks        []string      `koanf:"sinks"`
+		File         OutboxFile    `koanf:"file"`
+		Webhook      OutboxWebhook `koanf:"webhook"`
+	}
+
+	// Absences.CheckInterval is how often started absences are processed when jobs.absences.schedule
+	// is not set; zero disables the processing.
+	Absences struct {
+		CheckInterval time.Duration `koanf:"check_interval"`
+	}
+
+	// Jobs hold cron schedules of background jobs; an empty schedule disables the job.
+	Jobs struct {
+		StaleReviews  StaleReviewsJob `koanf:"stale_reviews"`
+		Absences      Job             `koanf:"absences"`
+		StatsSnapshot Job             `koanf:"stats_snapshot"`
+		ReviewSLA     ReviewSLAJob    `koanf:"review_sla"`
+		// RateLimitCleanup only runs with the postgres rate limit backend.
+		RateLimitCleanup   Job `koanf:"rate_limit_cleanup"`
+		IdempotencyCleanup Job `koanf:"idempotency_cleanup"`
+	}
+
+	Job struct {
+		Schedule string `koanf:"schedule"`
+	}
+
+	// ReviewSLAJob reassigns reviews pending longer than ReassignFactor times the team SLA;
+	// the factor must be at least 1.
+	ReviewSLAJob struct {
+		Schedule       string  `koanf:"schedule"`
+		ReassignFactor float64 `koanf:"reassign_factor"`
+	}
+
+	// StaleReviewsJob escalates reviews past their team SLA; After is the deadline for teams without one.
+	StaleReviewsJob struct {
+		Schedule string        `koanf:"schedule"`
+		After    time.Duration `koanf:"after"`
+	}
+
+	// Tracing selects the span exporter: none, stdout or otlp (OTLP over HTTP to Endpoint).
+	Tracing struct {
+		Exporter    string  `koanf:"exporter"`
+		Endpoint    string  `koanf:"endpoint"`
+		Insecure    bool    `koanf:"insecure"`
+		SampleRatio float64 `koanf:"sample_ratio"`
+	}
+
+	// Auth selects how callers are authenticated: none lets everyone in as admin, token requires
+	// a static admin token or a per-user API token, jwt requires a JWT signed by the SSO provider.
+	Auth struct {
+		Mode        string   `koanf:"mode"`
+		AdminTokens []string `koanf:"admin_tokens"`
+		JWT         AuthJWT  `koanf:"jwt"`
+	}
+
+	// AuthJWT verifies SSO tokens against a JWKS from JWKSFile or JWKSURL.
+	AuthJWT struct {
+		JWKSFile        string            `koanf:"jwks_file"`
+		JWKSURL         string            `koanf:"jwks_url"`
+		RefreshInterval time.Duration     `koanf:"refresh_interval"`
+		Issuer          string            `koanf:"issuer"`
+		Audience        string            `koanf:"audience"`
+		UserClaim       string            `koanf:"user_claim"`
+		RoleClaim       string            `koanf:"role_claim"`
+		RoleMapping     map[string]string `koanf:"role_mapping"`
+		DefaultRole     string            `koanf:"default_role"`
+		Leeway          time.Duration     `koanf:"leeway"`
+	}
+
+	// RateLimit throttles each caller with token buckets: none turns it off, memory keeps buckets per
+	// replica, postgres shares them between replicas. Endpoints override the default rule by path.
+	// Address limits each IP before authentication.
+	RateLimit struct {
+		Backend   string                   `koanf:"backend"`
+		Rate      float64                  `koanf:"rate"`
+		Burst     int                      `koanf:"burst"`
+		Endpoints map[string]RateLimitRule `koanf:"endpoints"`
+		Address   RateLimitRule            `koanf:"address"`
+	}
+
+	RateLimitRule struct {
+		Rate  float64 `koanf:"rate"`
+		Burst int     `koanf:"burst"`
+	}
+
+	// Idempotency keeps responses to POST requests sent with an Idempotency-Key for TTL. A request
+	// that has not finished within Lease no longer blocks retries with its key. Keyed requests with
+	// a body over MaxBodySize bytes are rejected.
+	Idempotency struct {
+		TTL         time.Duration `koanf:"ttl"`
+		Lease       time.Duration `koanf:"lease"`
+		MaxBodySize int64         `koanf:"max_body_size"`
+	}
+
+	OutboxFile struct {
+		Path string `koanf:"path"`
+	}
+
+	OutboxWebhook struct {
+		URL     string        `koanf:"url"`
+		Secret  string        `koanf:"secret"`
+		Timeout time.Duration `koanf:"timeout"`
+	}
 )
 
 var (
@@ -116,9 +253,46 @@ func Load(configPath string) (*Config, error) {
 		cfg.Postgres.DSN = DSN(cfg.Postgres)
 	}
 
+	// absences.check_interval predates the scheduler and still sets how often the absences job runs
+	if cfg.Jobs.Absences.Schedule == "" && cfg.Absences.CheckInterval > 0 {
+		cfg.Jobs.Absences.Schedule = "@every " + cfg.Absences.CheckInterval.String()
+	}
+
+	// an env var arrives as a single comma separated value
+	cfg.Auth.AdminTokens = splitList(cfg.Auth.AdminTokens)
+	cfg.HTTP.TrustedProxies = splitList(cfg.HTTP.TrustedProxies)
+
+	if err := validate(&cfg); err != nil {
+		return nil, fmt.Errorf("config: %w", err)
+	}
+
 	return &cfg, nil
 }
 
+// validate rejects values that would make the service misbehave rather than fail.
+func validate(cfg *Config) error {
+	// below 1 reviews would be reassigned before their SLA has even run out
+	if factor := cfg.Jobs.ReviewSLA.ReassignFactor; factor < 1 {
+		return fmt.Errorf("jobs.review_sla.reassign_factor must be at least 1, got %v", factor)
+	}
+
+	return nil
+}
+
+func splitList(values []string) []string {
+	var items []string
+
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+
+	return items
+}
+
 func loadDefaults(k *koanf.Koanf) error {
 	defaults := Config{
 		App: App{
@@ -131,6 +305,7 @@ func loadDefaults(k *koanf.Koanf) error {
 			Port:         8080,
 			ReadTimeout:  5 * time.Second,
 			WriteTimeout: 10 * time.Second,
+			DrainDelay:   5 * time.Second,
 		},
 		Postgres: Postgres{
 			Host:     "localhost",
@@ -140,6 +315,80 @@ func loadDefaults(k *koanf.Koanf) error {
 			DBName:   "service-reviewer",
 			DSN:      "",
 		},
+		Reviewers: Reviewers{
+			Strategy: "random",
+		},
+		Webhooks: Webhooks{
+			PollInterval: time.Second,
+			BatchSize:    50,
+			MaxAttempts:  8,
+			BaseBackoff:  5 * time.Second,
+			MaxBackoff:   time.Hour,
+			Timeout:      5 * time.Second,
+		},
+		Outbox: Outbox{
+			PollInterval: time.Second,
+			BatchSize:    100,
+			RetryDelay:   10 * time.Second,
+			Sinks:        []string{"log"},
+			File: OutboxFile{
+				Path: "outbox.jsonl",
+			},
+			Webhook: OutboxWebhook{
+				Timeout: 5 * time.Second,
+			},
+		},
+		Absences: Absences{
+			CheckInterval: time.Minute,
+		},
+		Jobs: Jobs{
+			StaleReviews: StaleReviewsJob{
+				Schedule: "@every 15m",
+				After:    48 * time.Hour,
+			},
+			StatsSnapshot: Job{
+				Schedule: "@daily",
+			},
+			ReviewSLA: ReviewSLAJob{
+				ReassignFactor: 2,
+			},
+			RateLimitCleanup: Job{
+				Schedule: "@hourly",
+			},
+			IdempotencyCleanup: Job{
+				Schedule: "@hourly",
+			},
+		},
+		Tracing: Tracing{
+			Exporter:    "none",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			SampleRatio: 1,
+		},
+		Auth: Auth{
+			Mode: "token",
+			JWT: AuthJWT{
+				RefreshInterval: time.Hour,
+				UserClaim:       "sub",
+				RoleClaim:       "role",
+				DefaultRole:     "member",
+				Leeway:          30 * time.Second,
+			},
+		},
+		RateLimit: RateLimit{
+			Backend: "memory",
+			Rate:    50,
+			Burst:   100,
+			Address: RateLimitRule{
+				Rate:  100,
+				Burst: 200,
+			},
+		},
+		Idempotency: Idempotency{
+			TTL:         24 * time.Hour,
+			Lease:       time.Minute,
+			MaxBodySize: 1 << 20,
+		},
 	}
 
 	return k.Load(structs.Provider(defaults, "koanf"), nil)
@@ -0,0 +1,84 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AdvisoryLocker serializes work across replicas with session level Postgres advisory locks.
+type AdvisoryLocker struct {
+	pool *pgxpool.Pool
+}
+
+func NewAdvisoryLocker(pool *pgxpool.Pool) *AdvisoryLocker {
+	return &AdvisoryLocker{pool: pool}
+}
+
+// TryWithLock runs fn only if the lock named key is free and reports whether fn was run.
+// The lock is held on a dedicated connection for the duration of fn.
+func (l *AdvisoryLocker) TryWithLock(ctx context.Context, key string, fn func(ctx context.Context) error) (bool, error) {
+	conn, err := l.pool.Acquire(ctx)
+	if err != nil {
+		return false, fmt.Errorf("acquire connection: %w", err)
+	}
+	defer conn.Release()
+
+	var locked bool
+	if err := conn.QueryRow(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, key).Scan(&locked); err != nil {
+		return false, fmt.Errorf("advisory lock: %w", err)
+	}
+
+	if !locked {
+		return false, nil
+	}
+
+	defer func() {
+		// the job context may already be cancelled, the lock must still be released
+		if _, err := conn.Exec(context.WithoutCancel(ctx), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, key); err != nil {
+			// closing the session releases every lock it holds
+			_ = conn.Conn().Close(context.WithoutCancel(ctx))
+		}
+	}()
+
+	return true, fn(ctx)
+}
+
+// JobRuns records the last handled tick of every scheduled job, so replicas whose timers fire
+// at different moments still run a job once per tick.
+type JobRuns struct {
+	pool *pgxpool.Pool
+}
+
+func NewJobRuns(pool *pgxpool.Pool) *JobRuns {
+	return &JobRuns{pool: pool}
+}
+
+// ClaimTick marks tick as handled unless a run at or after tick minus minGap has already been recorded,
+// and reports whether the caller should run the job. Callers hold the job's lock, so a tick is never
+// recorded by a replica that does not get to run it.
+func (r *JobRuns) ClaimTick(ctx context.Context, job string, tick time.Time, minGap time.Duration) (bool, error) {
+	const query = `
+		INSERT INTO scheduled_job_runs (job_name, last_run_at)
+		VALUES ($1, $2)
+		ON CONFLICT (job_name) DO UPDATE
+		SET last_run_at = EXCLUDED.last_run_at
+		WHERE scheduled_job_runs.last_run_at < $2::TIMESTAMPTZ - make_interval(secs => $3)
+		RETURNING TRUE
+	`
+
+	var claimed bool
+	err := r.pool.QueryRow(ctx, query, job, tick, minGap.Seconds()).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claim job tick: %w", err)
+	}
+
+	return claimed, nil
+}
@@ -0,0 +1,26 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrationVersion returns the newest goose migration applied to the database.
+// A version whose last record is a rollback is not counted.
+func MigrationVersion(ctx context.Context, q Querier) (int64, error) {
+	const query = `
+		SELECT COALESCE(MAX(version_id), 0)
+		FROM (
+			SELECT DISTINCT ON (version_id) version_id, is_applied
+			FROM goose_db_version
+			ORDER BY version_id, id DESC
+		) v
+		WHERE is_applied`
+
+	var version int64
+	if err := q.QueryRow(ctx, query).Scan(&version); err != nil {
+		return 0, fmt.Errorf("migration version: %w", err)
+	}
+
+	return version, nil
+}
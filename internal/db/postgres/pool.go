@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"mor80/service-reviewer/internal/config"
+	"mor80/service-reviewer/internal/tracing"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -20,6 +21,7 @@ func NewPool(ctx context.Context, cfg config.Postgres) (*pgxpool.Pool, error) {
 	pgxCfg.MinConns = 2
 	pgxCfg.MaxConnLifetime = time.Hour
 	pgxCfg.MaxConnIdleTime = 30 * time.Minute
+	pgxCfg.ConnConfig.Tracer = tracing.NewPgxTracer()
 
 	var pool *pgxpool.Pool
 	const attempts = 5
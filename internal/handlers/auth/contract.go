@@ -0,0 +1,14 @@
+package auth
+
+import (
+	"context"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type authService interface {
+	Authenticate(ctx context.Context, rawToken string) (*model.Principal, error)
+	IssueToken(ctx context.Context, token model.APIToken) (*model.APIToken, error)
+	ListTokens(ctx context.Context, userID string) ([]model.APIToken, error)
+	RevokeToken(ctx context.Context, tokenID int64) error
+}
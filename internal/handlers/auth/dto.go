@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type createTokenRequest struct {
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name"`
+	Role      model.Role `json:"role"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type revokeTokenRequest struct {
+	TokenID int64 `json:"token_id"`
+}
+
+type tokenResponse struct {
+	Token *model.APIToken `json:"token"`
+}
+
+type listResponse struct {
+	UserID string           `json:"user_id"`
+	Tokens []model.APIToken `json:"tokens"`
+}
@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"mor80/service-reviewer/internal/handlers/shared"
+	"mor80/service-reviewer/internal/model"
+)
+
+const (
+	errorCodeBadRequest = "BAD_REQUEST"
+	errorCodeInternal   = "INTERNAL_ERROR"
+)
+
+type AuthHandler struct {
+	service authService
+}
+
+func New(service authService) *AuthHandler {
+	return &AuthHandler{service: service}
+}
+
+func (h *AuthHandler) Register(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(RequireRole(model.RoleAdmin))
+
+		r.Post("/auth/tokens/create", h.create)
+		r.Get("/auth/tokens/list", h.list)
+		r.Post("/auth/tokens/revoke", h.revoke)
+	})
+}
+
+func (h *AuthHandler) create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req createTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.UserID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "user_id is required")
+		return
+	}
+
+	if req.Role == "" {
+		req.Role = model.RoleMember
+	}
+
+	if !req.Role.Valid() {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "role must be one of member, lead, admin")
+		return
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "expires_at must be in the future")
+		return
+	}
+
+	token, err := h.service.IssueToken(r.Context(), model.APIToken{
+		UserID:    req.UserID,
+		Name:      req.Name,
+		Role:      req.Role,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, tokenResponse{Token: token})
+}
+
+func (h *AuthHandler) list(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "user_id is required")
+		return
+	}
+
+	tokens, err := h.service.ListTokens(r.Context(), userID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	if tokens == nil {
+		tokens = []model.APIToken{}
+	}
+
+	shared.WriteJSON(w, http.StatusOK, listResponse{UserID: userID, Tokens: tokens})
+}
+
+func (h *AuthHandler) revoke(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req revokeTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TokenID <= 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "token_id is required")
+		return
+	}
+
+	if err := h.service.RevokeToken(r.Context(), req.TokenID); err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapError(err error) (int, string, string) {
+	var domainErr model.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code {
+		case model.ErrorCodeNotFound:
+			return http.StatusNotFound, string(domainErr.Code), domainErr.Message
+		case model.ErrorCodeForbidden:
+			return http.StatusForbidden, string(domainErr.Code), domainErr.Message
+		case model.ErrorCodeUnauthorized:
+			return http.StatusUnauthorized, string(domainErr.Code), domainErr.Message
+		default:
+			return http.StatusBadRequest, string(domainErr.Code), domainErr.Message
+		}
+	}
+
+	return http.StatusInternalServerError, errorCodeInternal, "internal server error"
+}
@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"mor80/service-reviewer/internal/handlers/shared"
+	"mor80/service-reviewer/internal/model"
+)
+
+type authenticator interface {
+	Authenticate(ctx context.Context, rawToken string) (*model.Principal, error)
+}
+
+// Authenticate rejects requests without a valid "Authorization: Bearer <token>" header
+// and stores the caller in the request context.
+func Authenticate(authn authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, err := authn.Authenticate(r.Context(), bearerToken(r))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="service-reviewer"`)
+				status, code, msg := mapError(err)
+				shared.WriteError(w, status, code, msg)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(model.WithPrincipal(r.Context(), *principal)))
+		})
+	}
+}
+
+// Anonymous treats every caller as admin. It is used when authentication is turned off.
+func Anonymous(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(model.WithPrincipal(r.Context(), model.Principal{Role: model.RoleAdmin})))
+	})
+}
+
+// RequireRole lets through callers whose role is at least role. It must run after Authenticate or Anonymous.
+func RequireRole(role model.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := model.PrincipalFrom(r.Context())
+			if !ok {
+				shared.WriteError(w, http.StatusUnauthorized, string(model.ErrorCodeUnauthorized), model.ErrUnauthorized.Message)
+				return
+			}
+
+			if !principal.Role.Allows(role) {
+				shared.WriteError(w, http.StatusForbidden, string(model.ErrorCodeForbidden), "requires "+string(role)+" role")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
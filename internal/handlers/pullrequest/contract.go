@@ -9,6 +9,14 @@ import (
 type pullRequestService interface {
 	Create(ctx context.Context, pr model.PullRequest) (*model.PullRequest, error)
 	Merge(ctx context.Context, prID string) (*model.PullRequest, error)
+	Close(ctx context.Context, prID string) (*model.PullRequest, error)
+	Reopen(ctx context.Context, prID string) (*model.PullRequest, error)
+	Ready(ctx context.Context, prID string) (*model.PullRequest, error)
+	Review(ctx context.Context, prID, reviewerID string, state model.ReviewState) (*model.PullRequest, error)
+	OverdueReviews(ctx context.Context, teamName string) ([]model.OverdueReview, error)
 	Reassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error)
-	AssignmentStats(ctx context.Context) ([]model.AssignmentStats, error)
+	PreviewReassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error)
+	History(ctx context.Context, prID string) ([]model.Event, error)
+	AssignmentStats(ctx context.Context, filter model.StatsFilter) ([]model.AssignmentStats, error)
+	StatsSummary(ctx context.Context, filter model.StatsFilter) (*model.ReviewStatsSummary, error)
 }
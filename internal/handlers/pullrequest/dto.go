@@ -6,15 +6,27 @@ type createRequest struct {
 	PullRequestID   string `json:"pull_request_id"`
 	PullRequestName string `json:"pull_request_name"`
 	AuthorID        string `json:"author_id"`
+	Draft           bool   `json:"draft"`
 }
 
 type mergeRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 }
 
+type statusRequest struct {
+	PullRequestID string `json:"pull_request_id"`
+}
+
+type reviewRequest struct {
+	PullRequestID string            `json:"pull_request_id"`
+	ReviewerID    string            `json:"reviewer_id"`
+	State         model.ReviewState `json:"state"`
+}
+
 type reassignRequest struct {
 	PullRequestID string `json:"pull_request_id"`
 	OldUserID     string `json:"old_user_id"`
+	DryRun        bool   `json:"dry_run"`
 }
 
 type prResponse struct {
@@ -24,4 +36,14 @@ type prResponse struct {
 type reassignResponse struct {
 	PR         *model.PullRequest `json:"pr"`
 	ReplacedBy string             `json:"replaced_by"`
+	DryRun     bool               `json:"dry_run,omitempty"`
+}
+
+type overdueResponse struct {
+	Reviews []model.OverdueReview `json:"reviews"`
+}
+
+type historyResponse struct {
+	PullRequestID string        `json:"pull_request_id"`
+	Events        []model.Event `json:"events"`
 }
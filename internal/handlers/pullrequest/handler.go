@@ -1,9 +1,12 @@
 package pullrequest
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 
@@ -27,8 +30,15 @@ func New(service pullRequestService) *PullRequestHandler {
 func (h *PullRequestHandler) Register(r chi.Router) {
 	r.Post("/pullRequest/create", h.create)
 	r.Post("/pullRequest/merge", h.merge)
+	r.Post("/pullRequest/close", h.changeStatus(h.service.Close))
+	r.Post("/pullRequest/reopen", h.changeStatus(h.service.Reopen))
+	r.Post("/pullRequest/ready", h.changeStatus(h.service.Ready))
+	r.Post("/pullRequest/review", h.review)
 	r.Post("/pullRequest/reassign", h.reassign)
+	r.Get("/pullRequest/history", h.history)
+	r.Get("/pullRequest/overdue", h.overdue)
 	r.Get("/stats/assignments", h.stats)
+	r.Get("/stats/summary", h.statsSummary)
 }
 
 func (h *PullRequestHandler) create(w http.ResponseWriter, r *http.Request) {
@@ -49,6 +59,10 @@ func (h *PullRequestHandler) create(w http.ResponseWriter, r *http.Request) {
 		ID:       req.PullRequestID,
 		Name:     req.PullRequestName,
 		AuthorID: req.AuthorID,
+		Status:   model.PullRequestStatusOpen,
+	}
+	if req.Draft {
+		pr.Status = model.PullRequestStatusDraft
 	}
 
 	created, err := h.service.Create(r.Context(), pr)
@@ -85,6 +99,67 @@ func (h *PullRequestHandler) merge(w http.ResponseWriter, r *http.Request) {
 	shared.WriteJSON(w, http.StatusOK, prResponse{PR: pr})
 }
 
+func (h *PullRequestHandler) changeStatus(change func(ctx context.Context, prID string) (*model.PullRequest, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req statusRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+			return
+		}
+
+		if req.PullRequestID == "" {
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "pull_request_id is required")
+			return
+		}
+
+		pr, err := change(r.Context(), req.PullRequestID)
+		if err != nil {
+			status, code, msg := mapError(err)
+			shared.WriteError(w, status, code, msg)
+			return
+		}
+
+		shared.WriteJSON(w, http.StatusOK, prResponse{PR: pr})
+	}
+}
+
+func (h *PullRequestHandler) review(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req reviewRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.PullRequestID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "pull_request_id is required")
+		return
+	}
+
+	// without reviewer_id the caller reviews as themselves, which needs a caller bound to a user
+	if principal, ok := model.PrincipalFrom(r.Context()); req.ReviewerID == "" && (!ok || principal.UserID == "") {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "reviewer_id is required")
+		return
+	}
+
+	if !req.State.Verdict() {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "state must be one of APPROVED, CHANGES_REQUESTED, COMMENTED")
+		return
+	}
+
+	pr, err := h.service.Review(r.Context(), req.PullRequestID, req.ReviewerID, req.State)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, prResponse{PR: pr})
+}
+
 func (h *PullRequestHandler) reassign(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -99,7 +174,12 @@ func (h *PullRequestHandler) reassign(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pr, replacedBy, err := h.service.Reassign(r.Context(), req.PullRequestID, req.OldUserID)
+	reassign := h.service.Reassign
+	if req.DryRun {
+		reassign = h.service.PreviewReassign
+	}
+
+	pr, replacedBy, err := reassign(r.Context(), req.PullRequestID, req.OldUserID)
 	if err != nil {
 		status, code, msg := mapError(err)
 		shared.WriteError(w, status, code, msg)
@@ -109,11 +189,53 @@ func (h *PullRequestHandler) reassign(w http.ResponseWriter, r *http.Request) {
 	shared.WriteJSON(w, http.StatusOK, reassignResponse{
 		PR:         pr,
 		ReplacedBy: replacedBy,
+		DryRun:     req.DryRun,
+	})
+}
+
+func (h *PullRequestHandler) history(w http.ResponseWriter, r *http.Request) {
+	prID := r.URL.Query().Get("pull_request_id")
+	if prID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "pull_request_id is required")
+		return
+	}
+
+	events, err := h.service.History(r.Context(), prID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, historyResponse{
+		PullRequestID: prID,
+		Events:        events,
 	})
 }
 
+func (h *PullRequestHandler) overdue(w http.ResponseWriter, r *http.Request) {
+	reviews, err := h.service.OverdueReviews(r.Context(), r.URL.Query().Get("team_name"))
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	if reviews == nil {
+		reviews = []model.OverdueReview{}
+	}
+
+	shared.WriteJSON(w, http.StatusOK, overdueResponse{Reviews: reviews})
+}
+
 func (h *PullRequestHandler) stats(w http.ResponseWriter, r *http.Request) {
-	stats, err := h.service.AssignmentStats(r.Context())
+	filter, err := parseStatsFilter(r)
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		return
+	}
+
+	stats, err := h.service.AssignmentStats(r.Context(), filter)
 	if err != nil {
 		status, code, msg := mapError(err)
 		shared.WriteError(w, status, code, msg)
@@ -123,16 +245,84 @@ func (h *PullRequestHandler) stats(w http.ResponseWriter, r *http.Request) {
 	shared.WriteJSON(w, http.StatusOK, stats)
 }
 
+func (h *PullRequestHandler) statsSummary(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseStatsFilter(r)
+	if err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, err.Error())
+		return
+	}
+
+	summary, err := h.service.StatsSummary(r.Context(), filter)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, summary)
+}
+
+// parseStatsFilter reads team_name, status and the from/to range (RFC 3339 or YYYY-MM-DD) from the query.
+func parseStatsFilter(r *http.Request) (model.StatsFilter, error) {
+	query := r.URL.Query()
+
+	filter := model.StatsFilter{
+		TeamName: query.Get("team_name"),
+		Status:   model.PullRequestStatus(query.Get("status")),
+	}
+
+	if filter.Status != "" && !filter.Status.Valid() {
+		return model.StatsFilter{}, fmt.Errorf("unknown status: %s", filter.Status)
+	}
+
+	var err error
+	if filter.From, err = parseStatsTime(query.Get("from")); err != nil {
+		return model.StatsFilter{}, fmt.Errorf("invalid from: %w", err)
+	}
+
+	if filter.To, err = parseStatsTime(query.Get("to")); err != nil {
+		return model.StatsFilter{}, fmt.Errorf("invalid to: %w", err)
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return model.StatsFilter{}, fmt.Errorf("to must be after from")
+	}
+
+	return filter, nil
+}
+
+func parseStatsTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t, err = time.Parse(time.DateOnly, value)
+		if err != nil {
+			return nil, fmt.Errorf("expected RFC 3339 time or YYYY-MM-DD")
+		}
+	}
+
+	return &t, nil
+}
+
 func mapError(err error) (int, string, string) {
 	var domainErr model.DomainError
 	if errors.As(err, &domainErr) {
 		switch domainErr.Code {
 		case model.ErrorCodeNotFound:
 			return http.StatusNotFound, string(domainErr.Code), domainErr.Message
+		case model.ErrorCodeForbidden:
+			return http.StatusForbidden, string(domainErr.Code), domainErr.Message
 		case model.ErrorCodePRExists,
 			model.ErrorCodePRMerged,
 			model.ErrorCodeNotAssigned,
-			model.ErrorCodeNoCandidate:
+			model.ErrorCodeNoCandidate,
+			model.ErrorCodePRNotOpen,
+			model.ErrorCodeInvalidTransition,
+			model.ErrorCodeNotApproved,
+			model.ErrorCodeMergeBlocked:
 			return http.StatusConflict, string(domainErr.Code), domainErr.Message
 		default:
 			return http.StatusBadRequest, string(domainErr.Code), domainErr.Message
@@ -2,7 +2,11 @@ package shared
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"strconv"
+
+	"mor80/service-reviewer/internal/model"
 )
 
 func WriteJSON(w http.ResponseWriter, status int, v any) {
@@ -19,3 +23,28 @@ func WriteError(w http.ResponseWriter, status int, code, message string) {
 		},
 	})
 }
+
+// ClientKey identifies the caller by API token, then by user, and falls back to the address set by
+// httpserver.RealIP for static admin tokens and disabled authentication.
+func ClientKey(r *http.Request) string {
+	if principal, ok := model.PrincipalFrom(r.Context()); ok {
+		switch {
+		case principal.TokenID != 0:
+			return "token:" + strconv.FormatInt(principal.TokenID, 10)
+		case principal.UserID != "":
+			return "user:" + principal.UserID
+		}
+	}
+
+	return "ip:" + ClientAddress(r)
+}
+
+// ClientAddress is the caller's IP as set by httpserver.RealIP, without the port.
+func ClientAddress(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
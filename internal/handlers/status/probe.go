@@ -0,0 +1,86 @@
+package status
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"mor80/service-reviewer/internal/handlers/shared"
+)
+
+const checkTimeout = 2 * time.Second
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// Check reports whether a dependency the service needs to serve traffic is usable.
+type Check struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+type CheckResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type ReadinessResponse struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Probe serves liveness and readiness. It stays not ready until SetReady(true) and should be
+// flipped back before shutdown so load balancers stop routing traffic while requests drain.
+type Probe struct {
+	ready  atomic.Bool
+	checks []Check
+}
+
+func NewProbe(checks ...Check) *Probe {
+	return &Probe{checks: checks}
+}
+
+func (p *Probe) SetReady(ready bool) {
+	p.ready.Store(ready)
+}
+
+// Livez reports that the process is up; it does not touch dependencies.
+func (p *Probe) Livez(w http.ResponseWriter, _ *http.Request) {
+	shared.WriteJSON(w, http.StatusOK, map[string]string{"status": StatusUp})
+}
+
+func (p *Probe) Readyz(w http.ResponseWriter, r *http.Request) {
+	resp := ReadinessResponse{
+		Status: StatusUp,
+		Checks: make(map[string]CheckResult, len(p.checks)),
+	}
+
+	for _, check := range p.checks {
+		ctx, cancel := context.WithTimeout(r.Context(), checkTimeout)
+		err := check.Check(ctx)
+		cancel()
+
+		if err != nil {
+			resp.Status = StatusDown
+			resp.Checks[check.Name] = CheckResult{Status: StatusDown, Error: err.Error()}
+			continue
+		}
+
+		resp.Checks[check.Name] = CheckResult{Status: StatusUp}
+	}
+
+	if !p.ready.Load() {
+		resp.Status = StatusDown
+		resp.Checks["serving"] = CheckResult{Status: StatusDown, Error: "service is starting or shutting down"}
+	}
+
+	status := http.StatusOK
+	if resp.Status != StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+
+	shared.WriteJSON(w, status, resp)
+}
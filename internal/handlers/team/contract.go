@@ -9,5 +9,12 @@ import (
 type teamService interface {
 	Create(ctx context.Context, team model.Team) (*model.Team, error)
 	Get(ctx context.Context, teamName string) (*model.Team, error)
-	DeactivateMembers(ctx context.Context, teamName string, userIDs []string) (*model.TeamDeactivationResult, error)
+	UpdateSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error)
+	SetReviewSLA(ctx context.Context, teamName string, minutes int) (*model.TeamSettings, error)
+	SetMergePolicy(ctx context.Context, teamName string, policy model.MergePolicy) (*model.TeamSettings, error)
+	SetBackupTeams(ctx context.Context, teamName string, backupTeams []string) (*model.TeamSettings, error)
+	SetRole(ctx context.Context, assignment model.TeamRoleAssignment) ([]model.TeamRoleAssignment, error)
+	RemoveRole(ctx context.Context, teamName, userID string) ([]model.TeamRoleAssignment, error)
+	ListRoles(ctx context.Context, teamName string) ([]model.TeamRoleAssignment, error)
+	DeactivateMembers(ctx context.Context, teamName string, userIDs []string, opts model.DeactivationOptions) (*model.TeamDeactivationResult, error)
 }
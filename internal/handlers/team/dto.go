@@ -18,10 +18,50 @@ type teamMemberObject struct {
 }
 
 type deactivateRequest struct {
-	TeamName string   `json:"team_name"`
-	UserIDs  []string `json:"user_ids"`
+	TeamName     string   `json:"team_name"`
+	UserIDs      []string `json:"user_ids"`
+	AllowPartial bool     `json:"allow_partial"`
+	DryRun       bool     `json:"dry_run"`
 }
 
 type deactivateResponse struct {
 	Result *model.TeamDeactivationResult `json:"result"`
 }
+
+type settingsRequest struct {
+	TeamName     string `json:"team_name"`
+	MinReviewers *int   `json:"min_reviewers"`
+	MaxReviewers *int   `json:"max_reviewers"`
+}
+
+type backupTeamsRequest struct {
+	TeamName    string   `json:"team_name"`
+	BackupTeams []string `json:"backup_teams"`
+}
+
+type mergePolicyRequest struct {
+	TeamName            string `json:"team_name"`
+	MinApprovals        int    `json:"min_approvals"`
+	RequireReviewer     bool   `json:"require_reviewer"`
+	RequireActiveAuthor bool   `json:"require_active_author"`
+}
+
+type reviewSLARequest struct {
+	TeamName         string `json:"team_name"`
+	ReviewSLAMinutes *int   `json:"review_sla_minutes"`
+}
+
+type settingsResponse struct {
+	Settings *model.TeamSettings `json:"settings"`
+}
+
+type roleRequest struct {
+	TeamName string         `json:"team_name"`
+	UserID   string         `json:"user_id"`
+	Role     model.TeamRole `json:"role"`
+}
+
+type rolesResponse struct {
+	TeamName string                     `json:"team_name"`
+	Roles    []model.TeamRoleAssignment `json:"roles"`
+}
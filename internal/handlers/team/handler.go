@@ -7,6 +7,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 
+	"mor80/service-reviewer/internal/handlers/auth"
 	"mor80/service-reviewer/internal/handlers/shared"
 	"mor80/service-reviewer/internal/model"
 )
@@ -25,9 +26,19 @@ func New(service teamService) *TeamHandler {
 }
 
 func (h *TeamHandler) Register(r chi.Router) {
-	r.Post("/team/add", h.add)
 	r.Get("/team/get", h.get)
+	r.Get("/team/roles", h.roles)
+
+	r.With(auth.RequireRole(model.RoleAdmin)).Post("/team/add", h.add)
+
+	// the service decides from the caller's team roles, so a member token of a team owner is enough
+	r.Post("/team/settings", h.settings)
+	r.Post("/team/backupTeams", h.backupTeams)
+	r.Post("/team/mergePolicy", h.mergePolicy)
+	r.Post("/team/reviewSLA", h.reviewSLA)
 	r.Post("/team/deactivateMembers", h.deactivateMembers)
+	r.Post("/team/setRole", h.setRole)
+	r.Post("/team/removeRole", h.removeRole)
 }
 
 func (h *TeamHandler) add(w http.ResponseWriter, r *http.Request) {
@@ -71,6 +82,214 @@ func (h *TeamHandler) get(w http.ResponseWriter, r *http.Request) {
 	shared.WriteJSON(w, http.StatusOK, team)
 }
 
+func (h *TeamHandler) settings(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req settingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.MinReviewers == nil || req.MaxReviewers == nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name, min_reviewers and max_reviewers are required")
+		return
+	}
+
+	if *req.MinReviewers < 0 || *req.MaxReviewers < *req.MinReviewers {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "min_reviewers must be non-negative and not greater than max_reviewers")
+		return
+	}
+
+	settings, err := h.service.UpdateSettings(r.Context(), model.TeamSettings{
+		TeamName:     req.TeamName,
+		MinReviewers: *req.MinReviewers,
+		MaxReviewers: *req.MaxReviewers,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, settingsResponse{Settings: settings})
+}
+
+func (h *TeamHandler) backupTeams(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req backupTeamsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name is required")
+		return
+	}
+
+	for _, backupTeam := range req.BackupTeams {
+		if backupTeam == req.TeamName {
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team cannot be its own backup")
+			return
+		}
+	}
+
+	settings, err := h.service.SetBackupTeams(r.Context(), req.TeamName, req.BackupTeams)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, settingsResponse{Settings: settings})
+}
+
+func (h *TeamHandler) mergePolicy(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req mergePolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name is required")
+		return
+	}
+
+	if req.MinApprovals < 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "min_approvals must not be negative")
+		return
+	}
+
+	settings, err := h.service.SetMergePolicy(r.Context(), req.TeamName, model.MergePolicy{
+		MinApprovals:        req.MinApprovals,
+		RequireReviewer:     req.RequireReviewer,
+		RequireActiveAuthor: req.RequireActiveAuthor,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, settingsResponse{Settings: settings})
+}
+
+func (h *TeamHandler) reviewSLA(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req reviewSLARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.ReviewSLAMinutes == nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name and review_sla_minutes are required")
+		return
+	}
+
+	if *req.ReviewSLAMinutes < 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "review_sla_minutes must not be negative")
+		return
+	}
+
+	settings, err := h.service.SetReviewSLA(r.Context(), req.TeamName, *req.ReviewSLAMinutes)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, settingsResponse{Settings: settings})
+}
+
+func (h *TeamHandler) roles(w http.ResponseWriter, r *http.Request) {
+	teamName := r.URL.Query().Get("team_name")
+	if teamName == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name is required")
+		return
+	}
+
+	roles, err := h.service.ListRoles(r.Context(), teamName)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	writeRoles(w, teamName, roles)
+}
+
+func (h *TeamHandler) setRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req roleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.UserID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name and user_id are required")
+		return
+	}
+
+	if !req.Role.Valid() {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "role must be owner or maintainer")
+		return
+	}
+
+	roles, err := h.service.SetRole(r.Context(), model.TeamRoleAssignment{
+		TeamName: req.TeamName,
+		UserID:   req.UserID,
+		Role:     req.Role,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	writeRoles(w, req.TeamName, roles)
+}
+
+func (h *TeamHandler) removeRole(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req roleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.TeamName == "" || req.UserID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "team_name and user_id are required")
+		return
+	}
+
+	roles, err := h.service.RemoveRole(r.Context(), req.TeamName, req.UserID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	writeRoles(w, req.TeamName, roles)
+}
+
+func writeRoles(w http.ResponseWriter, teamName string, roles []model.TeamRoleAssignment) {
+	if roles == nil {
+		roles = []model.TeamRoleAssignment{}
+	}
+
+	shared.WriteJSON(w, http.StatusOK, rolesResponse{TeamName: teamName, Roles: roles})
+}
+
 func (h *TeamHandler) deactivateMembers(w http.ResponseWriter, r *http.Request) {
 	defer r.Body.Close()
 
@@ -85,7 +304,10 @@ func (h *TeamHandler) deactivateMembers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	result, err := h.service.DeactivateMembers(r.Context(), req.TeamName, req.UserIDs)
+	result, err := h.service.DeactivateMembers(r.Context(), req.TeamName, req.UserIDs, model.DeactivationOptions{
+		AllowPartial: req.AllowPartial,
+		DryRun:       req.DryRun,
+	})
 	if err != nil {
 		status, code, msg := mapError(err)
 		shared.WriteError(w, status, code, msg)
@@ -115,6 +337,8 @@ func mapError(err error) (int, string, string) {
 		switch domainErr.Code {
 		case model.ErrorCodeNotFound:
 			return http.StatusNotFound, string(domainErr.Code), domainErr.Message
+		case model.ErrorCodeForbidden:
+			return http.StatusForbidden, string(domainErr.Code), domainErr.Message
 		case model.ErrorCodeTeamExists:
 			return http.StatusBadRequest, string(domainErr.Code), domainErr.Message
 		default:
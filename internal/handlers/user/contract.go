@@ -9,4 +9,8 @@ import (
 type userService interface {
 	SetIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error)
 	GetReview(ctx context.Context, userID string) ([]model.PullRequestShort, error)
+	History(ctx context.Context, userID string) ([]model.Event, error)
+	AddAbsence(ctx context.Context, absence model.Absence) (*model.Absence, error)
+	ListAbsences(ctx context.Context, userID string) ([]model.Absence, error)
+	DeleteAbsence(ctx context.Context, absenceID int64) error
 }
@@ -1,6 +1,10 @@
 package user
 
-import "mor80/service-reviewer/internal/model"
+import (
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+)
 
 type setIsActiveRequest struct {
 	UserID   string `json:"user_id"`
@@ -16,3 +20,28 @@ type getReviewResponse struct {
 	PullRequests []model.PullRequestShort `json:"pull_requests"`
 }
 
+type historyResponse struct {
+	UserID string        `json:"user_id"`
+	Events []model.Event `json:"events"`
+}
+
+type addAbsenceRequest struct {
+	UserID       string    `json:"user_id"`
+	StartsAt     time.Time `json:"starts_at"`
+	EndsAt       time.Time `json:"ends_at"`
+	Reason       string    `json:"reason"`
+	AutoReassign bool      `json:"auto_reassign"`
+}
+
+type absenceResponse struct {
+	Absence *model.Absence `json:"absence"`
+}
+
+type absencesResponse struct {
+	UserID   string          `json:"user_id"`
+	Absences []model.Absence `json:"absences"`
+}
+
+type deleteAbsenceRequest struct {
+	AbsenceID int64 `json:"absence_id"`
+}
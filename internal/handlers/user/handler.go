@@ -29,6 +29,10 @@ func New(service userService) *UserHandler {
 func (h *UserHandler) Register(r chi.Router) {
 	r.Post("/users/setIsActive", h.setIsActive)
 	r.Get("/users/getReview", h.getReview)
+	r.Get("/users/history", h.history)
+	r.Post("/users/addAbsence", h.addAbsence)
+	r.Get("/users/absences", h.absences)
+	r.Post("/users/deleteAbsence", h.deleteAbsence)
 }
 
 func (h *UserHandler) setIsActive(w http.ResponseWriter, r *http.Request) {
@@ -75,17 +79,121 @@ func (h *UserHandler) getReview(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (h *UserHandler) history(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, errorMissingUserID)
+		return
+	}
+
+	events, err := h.service.History(r.Context(), userID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, historyResponse{
+		UserID: userID,
+		Events: events,
+	})
+}
+
+func (h *UserHandler) addAbsence(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req addAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, errorInvalidJSON)
+		return
+	}
+
+	if req.UserID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, errorMissingUserID)
+		return
+	}
+
+	if req.StartsAt.IsZero() || !req.EndsAt.After(req.StartsAt) {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "starts_at is required and ends_at must be after it")
+		return
+	}
+
+	absence, err := h.service.AddAbsence(r.Context(), model.Absence{
+		UserID:       req.UserID,
+		StartsAt:     req.StartsAt,
+		EndsAt:       req.EndsAt,
+		Reason:       req.Reason,
+		AutoReassign: req.AutoReassign,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, absenceResponse{Absence: absence})
+}
+
+func (h *UserHandler) absences(w http.ResponseWriter, r *http.Request) {
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, errorMissingUserID)
+		return
+	}
+
+	absences, err := h.service.ListAbsences(r.Context(), userID)
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, absencesResponse{
+		UserID:   userID,
+		Absences: absences,
+	})
+}
+
+func (h *UserHandler) deleteAbsence(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req deleteAbsenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, errorInvalidJSON)
+		return
+	}
+
+	if req.AbsenceID <= 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "absence_id is required")
+		return
+	}
+
+	if err := h.service.DeleteAbsence(r.Context(), req.AbsenceID); err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 func mapError(err error) (int, string, string) {
 	var domainErr model.DomainError
 	if errors.As(err, &domainErr) {
 		switch domainErr.Code {
 		case model.ErrorCodeNotFound:
 			return http.StatusNotFound, string(domainErr.Code), domainErr.Message
+		case model.ErrorCodeForbidden:
+			return http.StatusForbidden, string(domainErr.Code), domainErr.Message
 		case model.ErrorCodeTeamExists,
 			model.ErrorCodePRExists,
 			model.ErrorCodePRMerged,
 			model.ErrorCodeNotAssigned,
-			model.ErrorCodeNoCandidate:
+			model.ErrorCodeNoCandidate,
+			model.ErrorCodePRNotOpen,
+			model.ErrorCodeInvalidTransition,
+			model.ErrorCodeNotApproved,
+			model.ErrorCodeMergeBlocked:
 			return http.StatusConflict, string(domainErr.Code), domainErr.Message
 		default:
 			return http.StatusBadRequest, string(domainErr.Code), domainErr.Message
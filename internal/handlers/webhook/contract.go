@@ -0,0 +1,13 @@
+package webhook
+
+import (
+	"context"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type webhookService interface {
+	Register(ctx context.Context, webhook model.Webhook) (*model.Webhook, error)
+	List(ctx context.Context) ([]model.Webhook, error)
+	Delete(ctx context.Context, webhookID int64) error
+}
@@ -0,0 +1,21 @@
+package webhook
+
+import "mor80/service-reviewer/internal/model"
+
+type registerRequest struct {
+	URL        string            `json:"url"`
+	Secret     string            `json:"secret"`
+	EventTypes []model.EventType `json:"event_types"`
+}
+
+type deleteRequest struct {
+	WebhookID int64 `json:"webhook_id"`
+}
+
+type webhookResponse struct {
+	Webhook *model.Webhook `json:"webhook"`
+}
+
+type listResponse struct {
+	Webhooks []model.Webhook `json:"webhooks"`
+}
@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi/v5"
+
+	"mor80/service-reviewer/internal/handlers/auth"
+	"mor80/service-reviewer/internal/handlers/shared"
+	"mor80/service-reviewer/internal/model"
+)
+
+const (
+	errorCodeBadRequest = "BAD_REQUEST"
+	errorCodeInternal   = "INTERNAL_ERROR"
+)
+
+type WebhookHandler struct {
+	service webhookService
+}
+
+func New(service webhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+func (h *WebhookHandler) Register(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(auth.RequireRole(model.RoleAdmin))
+
+		r.Post("/webhooks/register", h.register)
+		r.Get("/webhooks/list", h.list)
+		r.Post("/webhooks/delete", h.delete)
+	})
+}
+
+func (h *WebhookHandler) register(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req registerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.URL == "" || len(req.EventTypes) == 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "url and event_types are required")
+		return
+	}
+
+	if parsed, err := url.Parse(req.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "url must be an absolute http or https url")
+		return
+	}
+
+	for _, eventType := range req.EventTypes {
+		if !eventType.Valid() {
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "unknown event type: "+string(eventType))
+			return
+		}
+	}
+
+	webhook, err := h.service.Register(r.Context(), model.Webhook{
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+	})
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusCreated, webhookResponse{Webhook: webhook})
+}
+
+func (h *WebhookHandler) list(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.List(r.Context())
+	if err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	shared.WriteJSON(w, http.StatusOK, listResponse{Webhooks: webhooks})
+}
+
+func (h *WebhookHandler) delete(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var req deleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+		return
+	}
+
+	if req.WebhookID == 0 {
+		shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "webhook_id is required")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), req.WebhookID); err != nil {
+		status, code, msg := mapError(err)
+		shared.WriteError(w, status, code, msg)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func mapError(err error) (int, string, string) {
+	var domainErr model.DomainError
+	if errors.As(err, &domainErr) {
+		switch domainErr.Code {
+		case model.ErrorCodeNotFound:
+			return http.StatusNotFound, string(domainErr.Code), domainErr.Message
+		default:
+			return http.StatusBadRequest, string(domainErr.Code), domainErr.Message
+		}
+	}
+
+	return http.StatusInternalServerError, errorCodeInternal, "internal server error"
+}
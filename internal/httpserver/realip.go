@@ -0,0 +1,109 @@
+package httpserver
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RealIP sets the request's RemoteAddr to the client address reported by X-Forwarded-For or
+// X-Real-IP, but only when the request comes from one of the trusted proxies (IPs or CIDRs).
+// Anyone else could put any address there and dodge the per address rate limit.
+func RealIP(trustedProxies []string) (func(http.Handler) http.Handler, error) {
+	trusted := make([]netip.Prefix, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		prefix, err := parseProxy(strings.TrimSpace(proxy))
+		if err != nil {
+			return nil, fmt.Errorf("trusted proxy %q: %w", proxy, err)
+		}
+
+		trusted = append(trusted, prefix)
+	}
+
+	isTrusted := func(addr netip.Addr) bool {
+		for _, prefix := range trusted {
+			if prefix.Contains(addr.Unmap()) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if len(trusted) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			peer, ok := remoteAddr(r.RemoteAddr)
+			if !ok || !isTrusted(peer) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if client, ok := forwardedFor(r.Header, isTrusted); ok {
+				r.RemoteAddr = net.JoinHostPort(client.String(), "0")
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}, nil
+}
+
+// forwardedFor walks X-Forwarded-For from the nearest hop back and returns the first address not
+// added by a trusted proxy; the hops before it are client supplied and cannot be relied on.
+func forwardedFor(header http.Header, isTrusted func(netip.Addr) bool) (netip.Addr, bool) {
+	var hops []string
+	for _, value := range header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, false
+		}
+
+		if !isTrusted(addr) || i == 0 {
+			return addr.Unmap(), true
+		}
+	}
+
+	addr, err := netip.ParseAddr(strings.TrimSpace(header.Get("X-Real-IP")))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+func remoteAddr(remote string) (netip.Addr, bool) {
+	host, _, err := net.SplitHostPort(remote)
+	if err != nil {
+		host = remote
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+
+	return addr.Unmap(), true
+}
+
+func parseProxy(proxy string) (netip.Prefix, error) {
+	if strings.Contains(proxy, "/") {
+		return netip.ParsePrefix(proxy)
+	}
+
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
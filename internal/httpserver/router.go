@@ -2,35 +2,78 @@ package httpserver
 
 import (
 	"log/slog"
+	"net/http"
 
+	"mor80/service-reviewer/internal/handlers/auth"
 	"mor80/service-reviewer/internal/handlers/pullrequest"
 	"mor80/service-reviewer/internal/handlers/status"
 	"mor80/service-reviewer/internal/handlers/team"
 	"mor80/service-reviewer/internal/handlers/user"
+	"mor80/service-reviewer/internal/handlers/webhook"
+	"mor80/service-reviewer/internal/metrics"
+	"mor80/service-reviewer/internal/tracing"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
+// Middlewares are the request guards built from the config; the router decides where each applies.
+type Middlewares struct {
+	// RealIP resolves the client address behind trusted proxies, see RealIP.
+	RealIP func(http.Handler) http.Handler
+	// AddressLimit throttles by client address and runs before authentication.
+	AddressLimit func(http.Handler) http.Handler
+	Authenticate func(http.Handler) http.Handler
+	// RateLimit throttles by authenticated caller.
+	RateLimit  func(http.Handler) http.Handler
+	Idempotent func(http.Handler) http.Handler
+}
+
 func NewRouter(
 	logger *slog.Logger,
 	userHandler *user.UserHandler,
 	teamHandler *team.TeamHandler,
 	pullRequestHandler *pullrequest.PullRequestHandler,
+	webhookHandler *webhook.WebhookHandler,
+	authHandler *auth.AuthHandler,
+	mw Middlewares,
+	m *metrics.Metrics,
+	probe *status.Probe,
 ) *chi.Mux {
 	r := chi.NewRouter()
 
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
+	r.Use(mw.RealIP)
+	r.Use(tracing.Middleware)
 	r.Use(middleware.Logger)
 	r.Use(middleware.Recoverer)
+	r.Use(m.Middleware)
 
 	r.Get("/ping", status.Ping)
 	r.Head("/healthcheck", status.Healthcheck)
+	r.Get("/livez", probe.Livez)
+	r.Get("/readyz", probe.Readyz)
+	r.Method(http.MethodGet, "/metrics", m.Handler())
+
+	// everything below requires a caller, probes and metrics above stay public
+	r.Group(func(r chi.Router) {
+		// the address limit goes first so that rejected credentials are throttled as well
+		r.Use(mw.AddressLimit)
+		r.Use(mw.Authenticate)
+		r.Use(mw.RateLimit)
+
+		// token responses carry the raw secret, so they are never stored for replay
+		authHandler.Register(r)
+
+		r.Group(func(r chi.Router) {
+			r.Use(mw.Idempotent)
 
-	userHandler.Register(r)
-	teamHandler.Register(r)
-	pullRequestHandler.Register(r)
+			userHandler.Register(r)
+			teamHandler.Register(r)
+			pullRequestHandler.Register(r)
+			webhookHandler.Register(r)
+		})
+	})
 
 	return r
 }
@@ -0,0 +1,203 @@
+// Package idempotency replays the stored response when a POST request is retried with the same
+// Idempotency-Key, so a retry after a timeout does not create or reassign anything twice.
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	"mor80/service-reviewer/internal/handlers/shared"
+	"mor80/service-reviewer/internal/model"
+)
+
+const (
+	HeaderKey      = "Idempotency-Key"
+	HeaderReplayed = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+
+	errorCodeBadRequest      = "BAD_REQUEST"
+	errorCodeInternal        = "INTERNAL_ERROR"
+	errorCodePayloadTooLarge = "PAYLOAD_TOO_LARGE"
+)
+
+// Store keeps keys and responses. Every claim gets its own claimID, so a request that outlived its
+// lease cannot complete or release the key once a retry has taken it over.
+type Store interface {
+	Claim(ctx context.Context, clientKey, key, requestHash, claimID string, ttl, lease time.Duration) (bool, error)
+	Get(ctx context.Context, clientKey, key string) (*model.IdempotentResponse, error)
+	Complete(ctx context.Context, clientKey, key, claimID string, resp model.IdempotentResponse) error
+	Release(ctx context.Context, clientKey, key, claimID string) error
+}
+
+// Config sets how long responses are kept and how long a request may hold its key before
+// another attempt is allowed to take it over. Bodies of keyed requests are read into memory to be
+// hashed, so they are capped at MaxBodySize bytes.
+type Config struct {
+	TTL         time.Duration
+	Lease       time.Duration
+	MaxBodySize int64
+}
+
+type Replayer struct {
+	store  Store
+	config Config
+	logger *slog.Logger
+}
+
+func New(store Store, cfg Config, logger *slog.Logger) *Replayer {
+	return &Replayer{
+		store:  store,
+		config: cfg,
+		logger: logger,
+	}
+}
+
+// Middleware handles POST requests that carry an Idempotency-Key. The first request runs and its
+// response is stored; a retry with the same key and body gets that response back, the same key with
+// a different request is rejected. 5xx responses are not stored, so such requests can be retried.
+// Keys are scoped by caller, so it must run after authentication.
+func (rp *Replayer) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimSpace(r.Header.Get(HeaderKey))
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "idempotency key is too long")
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, rp.config.MaxBodySize))
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.As(err, &tooLarge):
+			shared.WriteError(w, http.StatusRequestEntityTooLarge, errorCodePayloadTooLarge, "request body is too large")
+			return
+		case err != nil:
+			shared.WriteError(w, http.StatusBadRequest, errorCodeBadRequest, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		client := shared.ClientKey(r)
+		hash := requestHash(r, body)
+
+		claimID, err := newClaimID()
+		if err != nil {
+			rp.logger.Error("idempotency claim id not generated", "err", err)
+			shared.WriteError(w, http.StatusInternalServerError, errorCodeInternal, "internal server error")
+			return
+		}
+
+		claimed, err := rp.store.Claim(r.Context(), client, key, hash, claimID, rp.config.TTL, rp.config.Lease)
+		if err != nil {
+			rp.logger.Error("idempotency key claim failed", "err", err)
+			shared.WriteError(w, http.StatusInternalServerError, errorCodeInternal, "internal server error")
+			return
+		}
+
+		if !claimed {
+			rp.replay(w, r, client, key, hash)
+			return
+		}
+
+		// the response is stored even if the client is gone, that is the point of the key
+		ctx := context.WithoutCancel(r.Context())
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		var buf bytes.Buffer
+		ww.Tee(&buf)
+
+		defer func() {
+			if p := recover(); p != nil {
+				rp.release(ctx, client, key, claimID)
+				panic(p)
+			}
+		}()
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		if status >= http.StatusInternalServerError {
+			rp.release(ctx, client, key, claimID)
+			return
+		}
+
+		err = rp.store.Complete(ctx, client, key, claimID, model.IdempotentResponse{
+			RequestHash: hash,
+			StatusCode:  status,
+			ContentType: ww.Header().Get("Content-Type"),
+			Body:        buf.Bytes(),
+		})
+		if err != nil {
+			rp.logger.Error("idempotent response not stored", "err", err)
+		}
+	})
+}
+
+func (rp *Replayer) replay(w http.ResponseWriter, r *http.Request, client, key, hash string) {
+	stored, err := rp.store.Get(r.Context(), client, key)
+
+	switch {
+	case errors.Is(err, model.ErrNotFound):
+		// the key expired between claim and lookup, the client may simply retry
+		w.Header().Set("Retry-After", "1")
+		shared.WriteError(w, http.StatusConflict, string(model.ErrorCodeRequestInProgress), "request with this idempotency key is in progress")
+	case err != nil:
+		rp.logger.Error("idempotent response lookup failed", "err", err)
+		shared.WriteError(w, http.StatusInternalServerError, errorCodeInternal, "internal server error")
+	case stored.RequestHash != hash:
+		shared.WriteError(w, http.StatusUnprocessableEntity, string(model.ErrorCodeIdempotencyReused), "idempotency key was already used for a different request")
+	case !stored.Completed():
+		w.Header().Set("Retry-After", "1")
+		shared.WriteError(w, http.StatusConflict, string(model.ErrorCodeRequestInProgress), "request with this idempotency key is in progress")
+	default:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(stored.StatusCode)
+		_, _ = w.Write(stored.Body)
+	}
+}
+
+func (rp *Replayer) release(ctx context.Context, client, key, claimID string) {
+	if err := rp.store.Release(ctx, client, key, claimID); err != nil {
+		rp.logger.Error("idempotency key release failed", "err", err)
+	}
+}
+
+func newClaimID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// requestHash fingerprints the request so a key reused for another endpoint or body is caught.
+func requestHash(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,18 @@
+package model
+
+import "time"
+
+// Absence is a period when a user must not be picked as a reviewer.
+type Absence struct {
+	ID           int64      `json:"absence_id"`
+	UserID       string     `json:"user_id"`
+	StartsAt     time.Time  `json:"starts_at"`
+	EndsAt       time.Time  `json:"ends_at"`
+	Reason       string     `json:"reason,omitempty"`
+	AutoReassign bool       `json:"auto_reassign"`
+	ReassignedAt *time.Time `json:"reassigned_at,omitempty"`
+}
+
+func (a Absence) ActiveAt(t time.Time) bool {
+	return !t.Before(a.StartsAt) && t.Before(a.EndsAt)
+}
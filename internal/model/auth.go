@@ -0,0 +1,72 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type Role string
+
+const (
+	RoleMember Role = "member"
+	RoleLead   Role = "lead"
+	RoleAdmin  Role = "admin"
+)
+
+// roleRanks orders roles so that a higher role is allowed everything a lower one is.
+var roleRanks = map[Role]int{
+	RoleMember: 1,
+	RoleLead:   2,
+	RoleAdmin:  3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Allows reports whether the role grants at least the permissions of required.
+func (r Role) Allows(required Role) bool {
+	return roleRanks[r] >= roleRanks[required]
+}
+
+// Principal is the authenticated caller of a request. Static admin tokens have no user.
+type Principal struct {
+	UserID   string
+	TeamName string
+	Role     Role
+	TokenID  int64
+}
+
+type APIToken struct {
+	ID         int64      `json:"token_id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	Role       Role       `json:"role"`
+	Token      string     `json:"token,omitempty"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
+
+// ActiveAt reports whether the token can be used at t.
+func (t APIToken) ActiveAt(at time.Time) bool {
+	if t.RevokedAt != nil {
+		return false
+	}
+
+	return t.ExpiresAt == nil || at.Before(*t.ExpiresAt)
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the caller set by the authentication middleware.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -1,16 +1,28 @@
 package model
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type ErrorCode string
 
 const (
-	ErrorCodeTeamExists  ErrorCode = "TEAM_EXISTS"
-	ErrorCodePRExists    ErrorCode = "PR_EXISTS"
-	ErrorCodePRMerged    ErrorCode = "PR_MERGED"
-	ErrorCodeNotAssigned ErrorCode = "NOT_ASSIGNED"
-	ErrorCodeNoCandidate ErrorCode = "NO_CANDIDATE"
-	ErrorCodeNotFound    ErrorCode = "NOT_FOUND"
+	ErrorCodeTeamExists        ErrorCode = "TEAM_EXISTS"
+	ErrorCodePRExists          ErrorCode = "PR_EXISTS"
+	ErrorCodePRMerged          ErrorCode = "PR_MERGED"
+	ErrorCodeNotAssigned       ErrorCode = "NOT_ASSIGNED"
+	ErrorCodeNoCandidate       ErrorCode = "NO_CANDIDATE"
+	ErrorCodeNotFound          ErrorCode = "NOT_FOUND"
+	ErrorCodePRNotOpen         ErrorCode = "PR_NOT_OPEN"
+	ErrorCodeInvalidTransition ErrorCode = "INVALID_TRANSITION"
+	ErrorCodeNotApproved       ErrorCode = "NOT_APPROVED"
+	ErrorCodeMergeBlocked      ErrorCode = "MERGE_BLOCKED"
+	ErrorCodeUnauthorized      ErrorCode = "UNAUTHORIZED"
+	ErrorCodeForbidden         ErrorCode = "FORBIDDEN"
+	ErrorCodeRateLimited       ErrorCode = "RATE_LIMITED"
+	ErrorCodeIdempotencyReused ErrorCode = "IDEMPOTENCY_KEY_REUSED"
+	ErrorCodeRequestInProgress ErrorCode = "REQUEST_IN_PROGRESS"
 )
 
 type DomainError struct {
@@ -40,4 +52,20 @@ var (
 	ErrNotAssigned = DomainError{Code: ErrorCodeNotAssigned, Message: "reviewer is not assigned to this pull request"}
 	ErrNoCandidate = DomainError{Code: ErrorCodeNoCandidate, Message: "no replacement candidate available"}
 	ErrNotFound    = DomainError{Code: ErrorCodeNotFound, Message: "resource not found"}
+	ErrPRNotOpen   = DomainError{Code: ErrorCodePRNotOpen, Message: "pull request is not open"}
+	ErrNotApproved = DomainError{Code: ErrorCodeNotApproved, Message: "pull request does not have enough approvals"}
+
+	ErrUnauthorized = DomainError{Code: ErrorCodeUnauthorized, Message: "missing or invalid token"}
 )
+
+func NewInvalidTransitionError(from, to PullRequestStatus) DomainError {
+	return NewDomainError(ErrorCodeInvalidTransition, fmt.Sprintf("cannot move pull request from %s to %s", from, to))
+}
+
+func NewForbiddenError(teamName string) DomainError {
+	return NewDomainError(ErrorCodeForbidden, fmt.Sprintf("not allowed to act on team %s", teamName))
+}
+
+func NewMergeBlockedError(unmet []string) DomainError {
+	return NewDomainError(ErrorCodeMergeBlocked, "merge policy not satisfied: "+strings.Join(unmet, "; "))
+}
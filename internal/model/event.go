@@ -0,0 +1,76 @@
+package model
+
+import (
+	"context"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventPullRequestCreated     EventType = "PR_CREATED"
+	EventPullRequestOpened      EventType = "PR_OPENED"
+	EventPullRequestMerged      EventType = "PR_MERGED"
+	EventPullRequestClosed      EventType = "PR_CLOSED"
+	EventReviewerAssigned       EventType = "REVIEWER_ASSIGNED"
+	EventReviewerReassigned     EventType = "REVIEWER_REASSIGNED"
+	EventReviewerRemoved        EventType = "REVIEWER_REMOVED"
+	EventReviewSubmitted        EventType = "REVIEW_SUBMITTED"
+	EventReviewEscalated        EventType = "REVIEW_ESCALATED"
+	EventUserActivated          EventType = "USER_ACTIVATED"
+	EventUserDeactivated        EventType = "USER_DEACTIVATED"
+	EventTeamMembersDeactivated EventType = "TEAM_MEMBERS_DEACTIVATED"
+)
+
+func (t EventType) Valid() bool {
+	switch t {
+	case EventPullRequestCreated,
+		EventPullRequestOpened,
+		EventPullRequestMerged,
+		EventPullRequestClosed,
+		EventReviewerAssigned,
+		EventReviewerReassigned,
+		EventReviewerRemoved,
+		EventReviewSubmitted,
+		EventReviewEscalated,
+		EventUserActivated,
+		EventUserDeactivated,
+		EventTeamMembersDeactivated:
+		return true
+	default:
+		return false
+	}
+}
+
+type Event struct {
+	ID            int64             `json:"event_id"`
+	Type          EventType         `json:"event_type"`
+	PullRequestID string            `json:"pull_request_id,omitempty"`
+	UserID        string            `json:"user_id,omitempty"`
+	Payload       map[string]string `json:"payload,omitempty"`
+	CreatedAt     time.Time         `json:"createdAt"`
+}
+
+// StatusEventType maps the status a pull request moved to onto its audit event.
+func StatusEventType(status PullRequestStatus) EventType {
+	switch status {
+	case PullRequestStatusMerged:
+		return EventPullRequestMerged
+	case PullRequestStatusClosed:
+		return EventPullRequestClosed
+	default:
+		return EventPullRequestOpened
+	}
+}
+
+type eventReasonKey struct{}
+
+// WithEventReason attaches a reason that is recorded with every event written under ctx.
+func WithEventReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, eventReasonKey{}, reason)
+}
+
+func EventReason(ctx context.Context) string {
+	reason, _ := ctx.Value(eventReasonKey{}).(string)
+	return reason
+}
@@ -0,0 +1,14 @@
+package model
+
+// IdempotentResponse is the stored outcome of a request sent with an Idempotency-Key.
+// StatusCode is zero while the first request is still being handled.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+func (r IdempotentResponse) Completed() bool {
+	return r.StatusCode != 0
+}
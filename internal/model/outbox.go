@@ -0,0 +1,15 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type OutboxMessage struct {
+	ID        int64           `json:"outbox_id"`
+	EventID   int64           `json:"event_id"`
+	EventType EventType       `json:"event_type"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Attempts  int             `json:"-"`
+}
@@ -8,19 +8,39 @@ import (
 type PullRequestStatus string
 
 const (
+	PullRequestStatusDraft  PullRequestStatus = "DRAFT"
 	PullRequestStatusOpen   PullRequestStatus = "OPEN"
 	PullRequestStatusMerged PullRequestStatus = "MERGED"
+	PullRequestStatusClosed PullRequestStatus = "CLOSED"
 )
 
+// pullRequestTransitions lists the statuses a pull request may move to from each status.
+var pullRequestTransitions = map[PullRequestStatus][]PullRequestStatus{
+	PullRequestStatusDraft:  {PullRequestStatusOpen, PullRequestStatusClosed},
+	PullRequestStatusOpen:   {PullRequestStatusMerged, PullRequestStatusClosed},
+	PullRequestStatusClosed: {PullRequestStatusOpen},
+	PullRequestStatusMerged: {},
+}
+
 func (s PullRequestStatus) Valid() bool {
 	switch s {
-	case PullRequestStatusOpen, PullRequestStatusMerged:
+	case PullRequestStatusDraft, PullRequestStatusOpen, PullRequestStatusMerged, PullRequestStatusClosed:
 		return true
 	default:
 		return false
 	}
 }
 
+func (s PullRequestStatus) CanTransitionTo(next PullRequestStatus) bool {
+	for _, allowed := range pullRequestTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (s PullRequestStatus) Validate() error {
 	if s.Valid() {
 		return nil
@@ -29,14 +49,43 @@ func (s PullRequestStatus) Validate() error {
 	return fmt.Errorf("invalid pull request status: %s", s)
 }
 
+type ReviewState string
+
+const (
+	ReviewStatePending          ReviewState = "PENDING"
+	ReviewStateApproved         ReviewState = "APPROVED"
+	ReviewStateChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewStateCommented        ReviewState = "COMMENTED"
+)
+
+// Verdict reports whether the state can be submitted by a reviewer.
+func (s ReviewState) Verdict() bool {
+	switch s {
+	case ReviewStateApproved, ReviewStateChangesRequested, ReviewStateCommented:
+		return true
+	default:
+		return false
+	}
+}
+
+type Review struct {
+	ReviewerID string      `json:"reviewer_id"`
+	State      ReviewState `json:"state"`
+	AssignedAt time.Time   `json:"assignedAt"`
+	ReviewedAt *time.Time  `json:"reviewedAt,omitempty"`
+}
+
 type PullRequest struct {
-	ID                string            `json:"pull_request_id"`
-	Name              string            `json:"pull_request_name"`
-	AuthorID          string            `json:"author_id"`
-	Status            PullRequestStatus `json:"status"`
-	AssignedReviewers []string          `json:"assigned_reviewers"`
-	CreatedAt         *time.Time        `json:"createdAt,omitempty"`
-	MergedAt          *time.Time        `json:"mergedAt,omitempty"`
+	ID                 string            `json:"pull_request_id"`
+	Name               string            `json:"pull_request_name"`
+	AuthorID           string            `json:"author_id"`
+	Status             PullRequestStatus `json:"status"`
+	AssignedReviewers  []string          `json:"assigned_reviewers"`
+	CrossTeamReviewers []string          `json:"cross_team_reviewers,omitempty"`
+	Reviews            []Review          `json:"reviews,omitempty"`
+	NeedsReviewers     bool              `json:"needs_reviewers"`
+	CreatedAt          *time.Time        `json:"createdAt,omitempty"`
+	MergedAt           *time.Time        `json:"mergedAt,omitempty"`
 }
 
 type PullRequestShort struct {
@@ -47,25 +96,54 @@ type PullRequestShort struct {
 }
 
 type PullRequestDB struct {
-	ID        string            `db:"pull_request_id"`
-	Name      string            `db:"pull_request_name"`
-	AuthorID  string            `db:"author_id"`
-	Status    PullRequestStatus `db:"status"`
-	CreatedAt *time.Time        `db:"created_at"`
-	MergedAt  *time.Time        `db:"merged_at"`
+	ID             string            `db:"pull_request_id"`
+	Name           string            `db:"pull_request_name"`
+	AuthorID       string            `db:"author_id"`
+	Status         PullRequestStatus `db:"status"`
+	NeedsReviewers bool              `db:"needs_reviewers"`
+	CreatedAt      *time.Time        `db:"created_at"`
+	MergedAt       *time.Time        `db:"merged_at"`
 }
 
 type PullRequestReviewerDB struct {
-	PullRequestID string `db:"pull_request_id"`
-	ReviewerID    string `db:"reviewer_id"`
+	PullRequestID string      `db:"pull_request_id"`
+	ReviewerID    string      `db:"reviewer_id"`
+	ReviewState   ReviewState `db:"review_state"`
+	ReviewedAt    *time.Time  `db:"reviewed_at"`
+}
+
+// OverdueReview is a pending review that has been assigned for longer than the author team's SLA.
+type OverdueReview struct {
+	PullRequestID    string    `json:"pull_request_id"`
+	PullRequestName  string    `json:"pull_request_name"`
+	AuthorID         string    `json:"author_id"`
+	ReviewerID       string    `json:"reviewer_id"`
+	TeamName         string    `json:"team_name"`
+	AssignedAt       time.Time `json:"assignedAt"`
+	ReviewSLAMinutes int       `json:"review_sla_minutes"`
+	OverdueMinutes   int       `json:"overdue_minutes"`
 }
 
 type AssignmentStats struct {
-	UserID string `json:"user_id"`
-	Count  int    `json:"assignment_count"`
+	UserID      string `json:"user_id"`
+	Count       int    `json:"assignment_count"`
+	OpenCount   int    `json:"open_count"`
+	MergedCount int    `json:"merged_count"`
 }
 
 type PullRequestAssignment struct {
 	PullRequestID string
 	ReviewerID    string
 }
+
+func (pr PullRequest) Approvals() int {
+	approvals := 0
+
+	for _, review := range pr.Reviews {
+		if review.State == ReviewStateApproved {
+			approvals++
+		}
+	}
+
+	return approvals
+}
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+// StatsFilter narrows statistics to pull requests authored by a team, created in [From, To)
+// and in the given status. Zero values do not filter.
+type StatsFilter struct {
+	TeamName string
+	From     *time.Time
+	To       *time.Time
+	Status   PullRequestStatus
+}
+
+type DurationStats struct {
+	Count      int     `json:"count"`
+	AvgMinutes float64 `json:"avg_minutes"`
+	P50Minutes float64 `json:"p50_minutes"`
+	P90Minutes float64 `json:"p90_minutes"`
+}
+
+type WeeklyLoad struct {
+	WeekStart time.Time `json:"week_start"`
+	UserID    string    `json:"user_id"`
+	Count     int       `json:"assignment_count"`
+}
+
+type ReviewStatsSummary struct {
+	PullRequests            int            `json:"pull_requests"`
+	TimeToFirstReview       DurationStats  `json:"time_to_first_review"`
+	TimeToMerge             DurationStats  `json:"time_to_merge"`
+	Reassignments           int            `json:"reassignments"`
+	ReassignmentsByReviewer map[string]int `json:"reassignments_by_reviewer"`
+	WeeklyLoad              []WeeklyLoad   `json:"weekly_load"`
+}
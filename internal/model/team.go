@@ -1,8 +1,28 @@
 package model
 
 type Team struct {
-	Name    string       `json:"team_name"`
-	Members []TeamMember `json:"members"`
+	Name         string       `json:"team_name"`
+	MinReviewers int          `json:"min_reviewers"`
+	MaxReviewers int          `json:"max_reviewers"`
+	BackupTeams  []string     `json:"backup_teams"`
+	MergePolicy  MergePolicy  `json:"merge_policy"`
+	Members      []TeamMember `json:"members"`
+}
+
+type TeamSettings struct {
+	TeamName     string      `json:"team_name"`
+	MinReviewers int         `json:"min_reviewers"`
+	MaxReviewers int         `json:"max_reviewers"`
+	BackupTeams  []string    `json:"backup_teams"`
+	MergePolicy  MergePolicy `json:"merge_policy"`
+	// ReviewSLAMinutes is how long a reviewer may keep a review pending; 0 disables the SLA.
+	ReviewSLAMinutes int `json:"review_sla_minutes"`
+}
+
+type MergePolicy struct {
+	MinApprovals        int  `json:"min_approvals"`
+	RequireReviewer     bool `json:"require_reviewer"`
+	RequireActiveAuthor bool `json:"require_active_author"`
 }
 
 type TeamMember struct {
@@ -11,12 +31,62 @@ type TeamMember struct {
 	IsActive bool   `json:"is_active"`
 }
 
+// TeamRole grants a user rights over a team, whichever team the user is a member of.
+type TeamRole string
+
+const (
+	TeamRoleOwner      TeamRole = "owner"
+	TeamRoleMaintainer TeamRole = "maintainer"
+)
+
+func (r TeamRole) Valid() bool {
+	return r == TeamRoleOwner || r == TeamRoleMaintainer
+}
+
+type TeamRoleAssignment struct {
+	TeamName string   `json:"team_name"`
+	UserID   string   `json:"user_id"`
+	Role     TeamRole `json:"role"`
+}
+
 type TeamDB struct {
-	Name string `db:"team_name"`
+	Name                     string `db:"team_name"`
+	MinReviewers             int    `db:"min_reviewers"`
+	MaxReviewers             int    `db:"max_reviewers"`
+	MergeMinApprovals        int    `db:"merge_min_approvals"`
+	MergeRequireReviewer     bool   `db:"merge_require_reviewer"`
+	MergeRequireActiveAuthor bool   `db:"merge_require_active_author"`
+}
+
+// DeactivationOptions control how DeactivateMembers treats reviews that cannot be reassigned.
+type DeactivationOptions struct {
+	AllowPartial bool
+	DryRun       bool
 }
 
 type TeamDeactivationResult struct {
-	TeamName          string   `json:"team_name"`
-	DeactivatedUserID []string `json:"deactivated_user_ids"`
-	ReassignedCount   int      `json:"reassigned_count"`
+	DryRun            bool                  `json:"dry_run"`
+	TeamName          string                `json:"team_name"`
+	DeactivatedUserID []string              `json:"deactivated_user_ids"`
+	ReassignedCount   int                   `json:"reassigned_count"`
+	DroppedCount      int                   `json:"dropped_count"`
+	FailedCount       int                   `json:"failed_count"`
+	Slots             []ReviewerSlotOutcome `json:"slots"`
+}
+
+type SlotOutcome string
+
+const (
+	SlotReassigned SlotOutcome = "REASSIGNED"
+	SlotDropped    SlotOutcome = "DROPPED"
+	SlotFailed     SlotOutcome = "FAILED"
+)
+
+// ReviewerSlotOutcome reports what happened to one open review of a deactivated member.
+type ReviewerSlotOutcome struct {
+	PullRequestID string      `json:"pull_request_id"`
+	OldReviewerID string      `json:"old_reviewer_id"`
+	Outcome       SlotOutcome `json:"outcome"`
+	ReplacedBy    string      `json:"replaced_by,omitempty"`
+	Error         string      `json:"error,omitempty"`
 }
@@ -0,0 +1,27 @@
+package model
+
+import "time"
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+type Webhook struct {
+	ID         int64       `json:"webhook_id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"secret,omitempty"`
+	EventTypes []EventType `json:"event_types"`
+	IsActive   bool        `json:"is_active"`
+	CreatedAt  time.Time   `json:"createdAt"`
+}
+
+type WebhookDelivery struct {
+	ID       int64
+	Webhook  Webhook
+	Event    Event
+	Attempts int
+}
@@ -0,0 +1,126 @@
+// Package ratelimit throttles callers with token buckets kept in memory or in a store shared by replicas.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mor80/service-reviewer/internal/handlers/shared"
+	"mor80/service-reviewer/internal/model"
+)
+
+// Store spends one token from the bucket under key, refilling it by rate tokens per second up to burst.
+// When the bucket is empty it reports how long until the next token.
+type Store interface {
+	Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+}
+
+// Rule allows Burst requests at once and Rate requests per second on average.
+type Rule struct {
+	Rate  float64
+	Burst int
+}
+
+func (r Rule) validate() error {
+	if r.Rate <= 0 || r.Burst < 1 {
+		return fmt.Errorf("rate must be positive and burst at least 1, got rate %v burst %d", r.Rate, r.Burst)
+	}
+
+	return nil
+}
+
+// Config holds the rule applied to every endpoint and per path overrides, e.g. "/pullRequest/create".
+// Each caller gets a separate bucket for every overridden endpoint and one shared bucket for the rest.
+// Address is checked before authentication with one bucket per IP, so that requests with bad or
+// missing credentials are throttled too.
+type Config struct {
+	Default   Rule
+	Endpoints map[string]Rule
+	Address   Rule
+}
+
+// RefillTime is the longest time an emptied bucket takes to fill up again. A bucket idle for longer
+// is indistinguishable from a missing one.
+func (c Config) RefillTime() time.Duration {
+	longest := math.Max(float64(c.Default.Burst)/c.Default.Rate, float64(c.Address.Burst)/c.Address.Rate)
+	for _, rule := range c.Endpoints {
+		longest = math.Max(longest, float64(rule.Burst)/rule.Rate)
+	}
+
+	return time.Duration(longest * float64(time.Second))
+}
+
+type Limiter struct {
+	store  Store
+	config Config
+	logger *slog.Logger
+}
+
+func New(store Store, cfg Config, logger *slog.Logger) (*Limiter, error) {
+	if err := cfg.Default.validate(); err != nil {
+		return nil, fmt.Errorf("rate limit: %w", err)
+	}
+
+	for path, rule := range cfg.Endpoints {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("rate limit %s: %w", path, err)
+		}
+	}
+
+	if err := cfg.Address.validate(); err != nil {
+		return nil, fmt.Errorf("rate limit per address: %w", err)
+	}
+
+	return &Limiter{
+		store:  store,
+		config: cfg,
+		logger: logger,
+	}, nil
+}
+
+// Middleware rejects callers that ran out of tokens with 429 and Retry-After. It must run after
+// authentication so that callers are told apart by token rather than by address.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, scope := l.config.Default, "*"
+		if endpoint, ok := l.config.Endpoints[r.URL.Path]; ok {
+			rule, scope = endpoint, r.URL.Path
+		}
+
+		l.limit(w, r, next, shared.ClientKey(r)+" "+scope, rule)
+	})
+}
+
+// AddressMiddleware applies the per address rule. It runs before authentication, which would
+// otherwise answer credential guessing as fast as it comes.
+func (l *Limiter) AddressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		l.limit(w, r, next, "addr:"+shared.ClientAddress(r), l.config.Address)
+	})
+}
+
+// limit spends a token from the bucket under key. A failing store lets requests through:
+// throttling is not worth an outage.
+func (l *Limiter) limit(w http.ResponseWriter, r *http.Request, next http.Handler, key string, rule Rule) {
+	allowed, retryAfter, err := l.store.Take(r.Context(), key, rule.Rate, rule.Burst)
+	if err != nil {
+		l.logger.Warn("rate limit check failed", "err", err)
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	if !allowed {
+		seconds := max(1, int(math.Ceil(retryAfter.Seconds())))
+		w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		shared.WriteError(w, http.StatusTooManyRequests, string(model.ErrorCodeRateLimited),
+			fmt.Sprintf("rate limit exceeded, retry in %ds", seconds))
+		return
+	}
+
+	next.ServeHTTP(w, r)
+}
@@ -0,0 +1,194 @@
+package absence
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/db/postgres"
+	"mor80/service-reviewer/internal/model"
+)
+
+const absenceColumns = `absence_id, user_id, starts_at, ends_at, reason, auto_reassign, reassigned_at`
+
+type AbsenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *AbsenceRepository {
+	return &AbsenceRepository{pool: pool}
+}
+
+func (r *AbsenceRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
+func (r *AbsenceRepository) Create(ctx context.Context, absence model.Absence) (*model.Absence, error) {
+	const query = `
+		INSERT INTO user_absences (user_id, starts_at, ends_at, reason, auto_reassign)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + absenceColumns
+
+	created, err := scanAbsence(r.db(ctx).QueryRow(ctx, query,
+		absence.UserID, absence.StartsAt, absence.EndsAt, absence.Reason, absence.AutoReassign,
+	))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *AbsenceRepository) ListByUser(ctx context.Context, userID string) ([]model.Absence, error) {
+	const query = `
+		SELECT ` + absenceColumns + `
+		FROM user_absences
+		WHERE user_id = $1
+		ORDER BY starts_at
+	`
+
+	return r.list(ctx, query, userID)
+}
+
+func (r *AbsenceRepository) GetByID(ctx context.Context, absenceID int64) (*model.Absence, error) {
+	const query = `
+		SELECT ` + absenceColumns + `
+		FROM user_absences
+		WHERE absence_id = $1
+	`
+
+	absence, err := scanAbsence(r.db(ctx).QueryRow(ctx, query, absenceID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return absence, nil
+}
+
+func (r *AbsenceRepository) Delete(ctx context.Context, absenceID int64) error {
+	const query = `
+		DELETE FROM user_absences
+		WHERE absence_id = $1
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, absenceID)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// ListUnavailable returns which of the given users have an absence covering the current moment.
+func (r *AbsenceRepository) ListUnavailable(ctx context.Context, userIDs []string) ([]string, error) {
+	const query = `
+		SELECT DISTINCT user_id
+		FROM user_absences
+		WHERE user_id = ANY($1) AND starts_at <= NOW() AND ends_at > NOW()
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return ids, nil
+}
+
+// ListDueReassignments returns started absences whose open reviews still have to be handed over.
+func (r *AbsenceRepository) ListDueReassignments(ctx context.Context) ([]model.Absence, error) {
+	const query = `
+		SELECT ` + absenceColumns + `
+		FROM user_absences
+		WHERE auto_reassign AND reassigned_at IS NULL AND starts_at <= NOW() AND ends_at > NOW()
+		ORDER BY starts_at
+	`
+
+	return r.list(ctx, query)
+}
+
+func (r *AbsenceRepository) MarkReassigned(ctx context.Context, absenceID int64, reassignedAt time.Time) error {
+	const query = `
+		UPDATE user_absences
+		SET reassigned_at = $2
+		WHERE absence_id = $1
+	`
+
+	if _, err := r.db(ctx).Exec(ctx, query, absenceID, reassignedAt); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AbsenceRepository) list(ctx context.Context, query string, args ...any) ([]model.Absence, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var absences []model.Absence
+
+	for rows.Next() {
+		absence, err := scanAbsence(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		absences = append(absences, *absence)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return absences, nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAbsence(row scanner) (*model.Absence, error) {
+	var a model.Absence
+
+	if err := row.Scan(&a.ID, &a.UserID, &a.StartsAt, &a.EndsAt, &a.Reason, &a.AutoReassign, &a.ReassignedAt); err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
@@ -0,0 +1,148 @@
+package apitoken
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/db/postgres"
+	"mor80/service-reviewer/internal/model"
+)
+
+const tokenColumns = `token_id, user_id, name, role, expires_at, last_used_at, revoked_at, created_at`
+
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+func (r *APITokenRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
+// Create stores a token by the hash of its secret; the secret itself is never persisted.
+func (r *APITokenRepository) Create(ctx context.Context, token model.APIToken, hash string) (*model.APIToken, error) {
+	const query = `
+		INSERT INTO api_tokens (user_id, name, token_hash, role, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + tokenColumns
+
+	created, err := scanToken(r.db(ctx).QueryRow(ctx, query, token.UserID, token.Name, hash, token.Role, token.ExpiresAt))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *APITokenRepository) GetByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	const query = `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE token_hash = $1
+	`
+
+	token, err := scanToken(r.db(ctx).QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return token, nil
+}
+
+func (r *APITokenRepository) ListByUser(ctx context.Context, userID string) ([]model.APIToken, error) {
+	const query = `
+		SELECT ` + tokenColumns + `
+		FROM api_tokens
+		WHERE user_id = $1
+		ORDER BY token_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []model.APIToken
+
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		tokens = append(tokens, *token)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (r *APITokenRepository) Revoke(ctx context.Context, tokenID int64, revokedAt time.Time) error {
+	const query = `
+		UPDATE api_tokens
+		SET revoked_at = COALESCE(revoked_at, $2)
+		WHERE token_id = $1
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, tokenID, revokedAt)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// Touch records that the token was used, at most once per touchInterval.
+func (r *APITokenRepository) Touch(ctx context.Context, tokenID int64, usedAt time.Time) error {
+	const query = `
+		UPDATE api_tokens
+		SET last_used_at = $2
+		WHERE token_id = $1 AND (last_used_at IS NULL OR last_used_at < $2)
+	`
+
+	if _, err := r.db(ctx).Exec(ctx, query, tokenID, usedAt); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row scanner) (*model.APIToken, error) {
+	var t model.APIToken
+
+	if err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Role, &t.ExpiresAt, &t.LastUsedAt, &t.RevokedAt, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,125 @@
+package event
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/db/postgres"
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/repository/postgres/outbox"
+)
+
+type EventRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *EventRepository {
+	return &EventRepository{pool: pool}
+}
+
+func (r *EventRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
+// Append writes events inside tx so they are committed together with the change they describe.
+// Deliveries for webhooks subscribed to an event and the outbox message are written in the same transaction.
+func Append(ctx context.Context, tx pgx.Tx, events ...model.Event) error {
+	const query = `
+		INSERT INTO events (event_type, pull_request_id, user_id, payload)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4)
+		RETURNING event_id, created_at
+	`
+
+	const enqueueQuery = `
+		INSERT INTO webhook_deliveries (webhook_id, event_id)
+		SELECT webhook_id, $1
+		FROM webhooks
+		WHERE is_active AND $2 = ANY(event_types)
+	`
+
+	reason := model.EventReason(ctx)
+
+	for _, event := range events {
+		// copied so the reason does not leak into a payload map the caller reuses
+		payload := make(map[string]string, len(event.Payload)+1)
+		for key, value := range event.Payload {
+			payload[key] = value
+		}
+
+		if _, ok := payload["reason"]; !ok && reason != "" {
+			payload["reason"] = reason
+		}
+
+		event.Payload = payload
+		if err := tx.QueryRow(ctx, query, event.Type, event.PullRequestID, event.UserID, payload).Scan(&event.ID, &event.CreatedAt); err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, enqueueQuery, event.ID, string(event.Type)); err != nil {
+			return err
+		}
+
+		if err := outbox.Add(ctx, tx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *EventRepository) ListByPullRequest(ctx context.Context, prID string) ([]model.Event, error) {
+	const query = `
+		SELECT event_id, event_type, COALESCE(pull_request_id, ''), COALESCE(user_id, ''), payload, created_at
+		FROM events
+		WHERE pull_request_id = $1
+		ORDER BY event_id
+	`
+
+	return r.list(ctx, query, prID)
+}
+
+func (r *EventRepository) ListByUser(ctx context.Context, userID string) ([]model.Event, error) {
+	const query = `
+		SELECT event_id, event_type, COALESCE(pull_request_id, ''), COALESCE(user_id, ''), payload, created_at
+		FROM events
+		WHERE user_id = $1
+		ORDER BY event_id
+	`
+
+	return r.list(ctx, query, userID)
+}
+
+func (r *EventRepository) list(ctx context.Context, query string, args ...any) ([]model.Event, error) {
+	rows, err := r.db(ctx).Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var events []model.Event
+
+	for rows.Next() {
+		var event model.Event
+		if err := rows.Scan(
+			&event.ID,
+			&event.Type,
+			&event.PullRequestID,
+			&event.UserID,
+			&event.Payload,
+			&event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		events = append(events, event)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return events, nil
+}
@@ -0,0 +1,120 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/db/postgres"
+	"mor80/service-reviewer/internal/model"
+)
+
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+func (r *IdempotencyRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
+// Claim reserves the key for a new request under claimID. An expired key, or one whose request never
+// completed within lease (the replica died midway), is taken over. It reports false if the key is held.
+func (r *IdempotencyRepository) Claim(ctx context.Context, clientKey, key, requestHash, claimID string, ttl, lease time.Duration) (bool, error) {
+	const query = `
+		INSERT INTO idempotency_keys (client_key, idempotency_key, request_hash, claim_id, expires_at)
+		VALUES ($1, $2, $3, $6, NOW() + make_interval(secs => $4))
+		ON CONFLICT (client_key, idempotency_key) DO UPDATE
+		SET request_hash = EXCLUDED.request_hash,
+		    claim_id = EXCLUDED.claim_id,
+		    status_code = NULL,
+		    content_type = '',
+		    response_body = NULL,
+		    created_at = NOW(),
+		    expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()
+		   OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= NOW() - make_interval(secs => $5))
+		RETURNING TRUE
+	`
+
+	var claimed bool
+	err := r.db(ctx).QueryRow(ctx, query, clientKey, key, requestHash, ttl.Seconds(), lease.Seconds(), claimID).Scan(&claimed)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("database error: %w", err)
+	}
+
+	return claimed, nil
+}
+
+func (r *IdempotencyRepository) Get(ctx context.Context, clientKey, key string) (*model.IdempotentResponse, error) {
+	const query = `
+		SELECT request_hash, COALESCE(status_code, 0), content_type, response_body
+		FROM idempotency_keys
+		WHERE client_key = $1 AND idempotency_key = $2 AND expires_at > NOW()
+	`
+
+	var resp model.IdempotentResponse
+	err := r.db(ctx).QueryRow(ctx, query, clientKey, key).Scan(&resp.RequestHash, &resp.StatusCode, &resp.ContentType, &resp.Body)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, model.ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return &resp, nil
+}
+
+// Complete stores the response of the request that claimed the key. A request whose claim was taken
+// over after its lease ran out changes nothing.
+func (r *IdempotencyRepository) Complete(ctx context.Context, clientKey, key, claimID string, resp model.IdempotentResponse) error {
+	const query = `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, response_body = $5
+		WHERE client_key = $1 AND idempotency_key = $2 AND claim_id = $6 AND status_code IS NULL
+	`
+
+	if _, err := r.db(ctx).Exec(ctx, query, clientKey, key, resp.StatusCode, resp.ContentType, resp.Body, claimID); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+// Release frees a key still held by claimID so that a retry runs the request again.
+func (r *IdempotencyRepository) Release(ctx context.Context, clientKey, key, claimID string) error {
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE client_key = $1 AND idempotency_key = $2 AND claim_id = $3 AND status_code IS NULL
+	`
+
+	if _, err := r.db(ctx).Exec(ctx, query, clientKey, key, claimID); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	const query = `
+		DELETE FROM idempotency_keys
+		WHERE expires_at <= NOW()
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
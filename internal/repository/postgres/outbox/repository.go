@@ -0,0 +1,107 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type OutboxRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *OutboxRepository {
+	return &OutboxRepository{pool: pool}
+}
+
+// Add writes an event to the outbox inside tx, so it is published only if the change commits.
+func Add(ctx context.Context, tx pgx.Tx, event model.Event) error {
+	const query = `
+		INSERT INTO outbox (event_id, event_type, payload)
+		VALUES ($1, $2, $3)
+	`
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, query, event.ID, event.Type, payload)
+	return err
+}
+
+// ClaimBatch locks unpublished messages for lease so other replicas skip them while they are relayed.
+func (r *OutboxRepository) ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	const query = `
+		WITH batch AS (
+			SELECT outbox_id
+			FROM outbox
+			WHERE published_at IS NULL AND (locked_until IS NULL OR locked_until <= NOW())
+			ORDER BY outbox_id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE outbox o
+		SET locked_until = NOW() + make_interval(secs => $2)
+		FROM batch
+		WHERE o.outbox_id = batch.outbox_id
+		RETURNING o.outbox_id, o.event_id, o.event_type, o.payload, o.created_at, o.attempts
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []model.OutboxMessage
+
+	for rows.Next() {
+		var m model.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.EventID, &m.EventType, &m.Payload, &m.CreatedAt, &m.Attempts); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		messages = append(messages, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, outboxID int64, publishedAt time.Time) error {
+	const query = `
+		UPDATE outbox
+		SET published_at = $2, attempts = attempts + 1, locked_until = NULL, last_error = NULL
+		WHERE outbox_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, outboxID, publishedAt); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, outboxID int64, retryAt time.Time, lastError string) error {
+	const query = `
+		UPDATE outbox
+		SET attempts = attempts + 1, locked_until = $2, last_error = $3
+		WHERE outbox_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, outboxID, retryAt, lastError); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
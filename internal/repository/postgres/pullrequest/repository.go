@@ -10,7 +10,9 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"mor80/service-reviewer/internal/db/postgres"
 	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/repository/postgres/event"
 )
 
 type PullRequestRepository struct {
@@ -21,18 +23,22 @@ func New(pool *pgxpool.Pool) *PullRequestRepository {
 	return &PullRequestRepository{pool: pool}
 }
 
+func (r *PullRequestRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
 func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequestDB, reviewerIDs []string) (*model.PullRequest, error) {
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.db(ctx).Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
 	prQuery := `
-		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, created_at, merged_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, needs_reviewers, created_at, merged_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
-	if _, err := tx.Exec(ctx, prQuery, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.CreatedAt, pr.MergedAt); err != nil {
+	if _, err := tx.Exec(ctx, prQuery, pr.ID, pr.Name, pr.AuthorID, pr.Status, pr.NeedsReviewers, pr.CreatedAt, pr.MergedAt); err != nil {
 		_ = tx.Rollback(ctx)
 
 		var pgErr *pgconn.PgError
@@ -48,11 +54,29 @@ func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest
 		VALUES ($1, $2)
 	`
 
+	events := []model.Event{{
+		Type:          model.EventPullRequestCreated,
+		PullRequestID: pr.ID,
+		UserID:        pr.AuthorID,
+		Payload:       map[string]string{"status": string(pr.Status)},
+	}}
+
 	for _, reviewerID := range reviewerIDs {
 		if _, err := tx.Exec(ctx, reviewersQuery, pr.ID, reviewerID); err != nil {
 			_ = tx.Rollback(ctx)
 			return nil, fmt.Errorf("database error: %w", err)
 		}
+
+		events = append(events, model.Event{
+			Type:          model.EventReviewerAssigned,
+			PullRequestID: pr.ID,
+			UserID:        reviewerID,
+		})
+	}
+
+	if err := event.Append(ctx, tx, events...); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -65,12 +89,12 @@ func (r *PullRequestRepository) Create(ctx context.Context, pr model.PullRequest
 
 func (r *PullRequestRepository) GetByID(ctx context.Context, prID string) (*model.PullRequest, error) {
 	const prQuery = `
-		SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		SELECT pull_request_id, pull_request_name, author_id, status, needs_reviewers, created_at, merged_at
 		FROM pull_requests
 		WHERE pull_request_id = $1
 	`
 
-	pr, err := scanPullRequest(r.pool.QueryRow(ctx, prQuery, prID))
+	pr, err := scanPullRequest(r.db(ctx).QueryRow(ctx, prQuery, prID))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, model.ErrNotFound
@@ -79,16 +103,16 @@ func (r *PullRequestRepository) GetByID(ctx context.Context, prID string) (*mode
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	reviewers, err := r.getReviewers(ctx, prID)
-	if err != nil {
+	if err := r.loadReviewers(ctx, pr); err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
-	pr.AssignedReviewers = reviewers
 
 	return pr, nil
 }
 
-func (r *PullRequestRepository) UpdateStatus(ctx context.Context, prID string, status model.PullRequestStatus, mergedAt *time.Time) (*model.PullRequest, error) {
+// UpdateStatus moves the pull request from status from to status to. If it is no longer in from,
+// because a concurrent request moved it first, nothing changes and an invalid transition is reported.
+func (r *PullRequestRepository) UpdateStatus(ctx context.Context, prID string, from, status model.PullRequestStatus, mergedAt *time.Time) (*model.PullRequest, error) {
 	if err := status.Validate(); err != nil {
 		return nil, err
 	}
@@ -96,30 +120,70 @@ func (r *PullRequestRepository) UpdateStatus(ctx context.Context, prID string, s
 	const query = `
 		UPDATE pull_requests
 		SET status = $2, merged_at = $3
-		WHERE pull_request_id = $1
-		RETURNING pull_request_id, pull_request_name, author_id, status, created_at, merged_at
+		WHERE pull_request_id = $1 AND status = $4
+		RETURNING pull_request_id, pull_request_name, author_id, status, needs_reviewers, created_at, merged_at
 	`
 
-	pr, err := scanPullRequest(r.pool.QueryRow(ctx, query, prID, status, mergedAt))
+	tx, err := r.db(ctx).Begin(ctx)
 	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	pr, err := scanPullRequest(tx.QueryRow(ctx, query, prID, status, mergedAt, from))
+	if err != nil {
+		_ = tx.Rollback(ctx)
+
 		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, model.ErrNotFound
+			return nil, r.transitionError(ctx, prID, status)
 		}
 
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	reviewers, err := r.getReviewers(ctx, prID)
-	if err != nil {
+	if err := event.Append(ctx, tx, model.Event{
+		Type:          model.StatusEventType(status),
+		PullRequestID: prID,
+		Payload:       map[string]string{"status": string(status)},
+	}); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := r.loadReviewers(ctx, pr); err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
-	pr.AssignedReviewers = reviewers
 
 	return pr, nil
 }
 
+// transitionError explains why a status update matched no row: the pull request is gone or has
+// already moved to a status that cannot go to status.
+func (r *PullRequestRepository) transitionError(ctx context.Context, prID string, status model.PullRequestStatus) error {
+	const query = `
+		SELECT status
+		FROM pull_requests
+		WHERE pull_request_id = $1
+	`
+
+	var current model.PullRequestStatus
+	err := r.db(ctx).QueryRow(ctx, query, prID).Scan(&current)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return model.ErrNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return model.NewInvalidTransitionError(current, status)
+}
+
 func (r *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (*model.PullRequest, error) {
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.db(ctx).Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -150,6 +214,175 @@ func (r *PullRequestRepository) ReplaceReviewer(ctx context.Context, prID, oldRe
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
+	if err := event.Append(ctx, tx,
+		model.Event{
+			Type:          model.EventReviewerReassigned,
+			PullRequestID: prID,
+			UserID:        oldReviewerID,
+			Payload:       map[string]string{"old_reviewer_id": oldReviewerID, "new_reviewer_id": newReviewerID},
+		},
+		model.Event{
+			Type:          model.EventReviewerAssigned,
+			PullRequestID: prID,
+			UserID:        newReviewerID,
+			Payload:       map[string]string{"replaced_reviewer_id": oldReviewerID},
+		},
+	); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return r.GetByID(ctx, prID)
+}
+
+func (r *PullRequestRepository) RemoveReviewer(ctx context.Context, prID, reviewerID string, needsReviewers bool) (*model.PullRequest, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const deleteQuery = `
+		DELETE FROM pull_request_reviewers
+		WHERE pull_request_id = $1 AND reviewer_id = $2
+	`
+
+	tag, err := tx.Exec(ctx, deleteQuery, prID, reviewerID)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return nil, model.ErrNotAssigned
+	}
+
+	const updateQuery = `
+		UPDATE pull_requests
+		SET needs_reviewers = $2
+		WHERE pull_request_id = $1
+	`
+
+	if _, err := tx.Exec(ctx, updateQuery, prID, needsReviewers); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := event.Append(ctx, tx, model.Event{
+		Type:          model.EventReviewerRemoved,
+		PullRequestID: prID,
+		UserID:        reviewerID,
+	}); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return r.GetByID(ctx, prID)
+}
+
+func (r *PullRequestRepository) AddReviewers(ctx context.Context, prID string, reviewerIDs []string, needsReviewers bool) (*model.PullRequest, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO pull_request_reviewers (pull_request_id, reviewer_id)
+		VALUES ($1, $2)
+		ON CONFLICT DO NOTHING
+	`
+
+	var events []model.Event
+
+	for _, reviewerID := range reviewerIDs {
+		tag, err := tx.Exec(ctx, insertQuery, prID, reviewerID)
+		if err != nil {
+			_ = tx.Rollback(ctx)
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		if tag.RowsAffected() > 0 {
+			events = append(events, model.Event{
+				Type:          model.EventReviewerAssigned,
+				PullRequestID: prID,
+				UserID:        reviewerID,
+			})
+		}
+	}
+
+	const updateQuery = `
+		UPDATE pull_requests
+		SET needs_reviewers = $2
+		WHERE pull_request_id = $1
+	`
+
+	tag, err := tx.Exec(ctx, updateQuery, prID, needsReviewers)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return nil, model.ErrNotFound
+	}
+
+	if err := event.Append(ctx, tx, events...); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return r.GetByID(ctx, prID)
+}
+
+func (r *PullRequestRepository) SetReviewState(ctx context.Context, prID, reviewerID string, state model.ReviewState, reviewedAt time.Time) (*model.PullRequest, error) {
+	const query = `
+		UPDATE pull_request_reviewers
+		SET review_state = $3, reviewed_at = $4
+		WHERE pull_request_id = $1 AND reviewer_id = $2
+	`
+
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, query, prID, reviewerID, state, reviewedAt)
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		_ = tx.Rollback(ctx)
+		return nil, model.ErrNotAssigned
+	}
+
+	if err := event.Append(ctx, tx, model.Event{
+		Type:          model.EventReviewSubmitted,
+		PullRequestID: prID,
+		UserID:        reviewerID,
+		Payload:       map[string]string{"state": string(state)},
+	}); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		_ = tx.Rollback(ctx)
 		return nil, fmt.Errorf("database error: %w", err)
@@ -167,7 +400,7 @@ func (r *PullRequestRepository) ListByReviewer(ctx context.Context, reviewerID s
 		ORDER BY pr.created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, reviewerID)
+	rows, err := r.db(ctx).Query(ctx, query, reviewerID)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -203,7 +436,7 @@ func (r *PullRequestRepository) ListOpenAssignmentsByReviewers(ctx context.Conte
 		WHERE pr.status = 'OPEN' AND prr.reviewer_id = ANY($1)
 	`
 
-	rows, err := r.pool.Query(ctx, query, reviewerIDs)
+	rows, err := r.db(ctx).Query(ctx, query, reviewerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -227,68 +460,223 @@ func (r *PullRequestRepository) ListOpenAssignmentsByReviewers(ctx context.Conte
 	return assignments, nil
 }
 
-func (r *PullRequestRepository) GetAssignmentStats(ctx context.Context) ([]model.AssignmentStats, error) {
+func (r *PullRequestRepository) CountOpenAssignmentsByReviewers(ctx context.Context, reviewerIDs []string) (map[string]int, error) {
+	counts := make(map[string]int, len(reviewerIDs))
+	if len(reviewerIDs) == 0 {
+		return counts, nil
+	}
+
 	const query = `
-		SELECT reviewer_id, COUNT(*) as count
-		FROM pull_request_reviewers
-		GROUP BY reviewer_id
-		ORDER BY count DESC
+		SELECT prr.reviewer_id, COUNT(*)
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
+		WHERE pr.status = 'OPEN' AND prr.reviewer_id = ANY($1)
+		GROUP BY prr.reviewer_id
 	`
 
-	rows, err := r.pool.Query(ctx, query)
+	rows, err := r.db(ctx).Query(ctx, query, reviewerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 	defer rows.Close()
 
-	var stats []model.AssignmentStats
+	for rows.Next() {
+		var (
+			reviewerID string
+			count      int
+		)
+		if err := rows.Scan(&reviewerID, &count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		counts[reviewerID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// EscalateStaleReviews flags open pull requests with a pending review past its deadline, so every pull
+// request is escalated once. The deadline is the author team's review SLA counted from assignment, the
+// same as for ListOverdueReviews; teams without an SLA use fallback, and none if it is not positive.
+// It returns the escalated pull request ids.
+func (r *PullRequestRepository) EscalateStaleReviews(ctx context.Context, fallback time.Duration) ([]string, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const query = `
+		UPDATE pull_requests pr
+		SET escalated_at = NOW()
+		FROM users au
+		JOIN teams t ON t.team_name = au.team_name
+		WHERE au.user_id = pr.author_id
+			AND pr.status = 'OPEN'
+			AND pr.escalated_at IS NULL
+			AND EXISTS (
+				SELECT 1
+				FROM pull_request_reviewers prr
+				WHERE prr.pull_request_id = pr.pull_request_id
+					AND prr.review_state = 'PENDING'
+					AND prr.assigned_at + CASE
+						WHEN t.review_sla_minutes > 0 THEN make_interval(mins => t.review_sla_minutes)
+						WHEN $1::float8 > 0 THEN make_interval(secs => $1::float8)
+					END <= NOW()
+			)
+		RETURNING pr.pull_request_id, pr.author_id, (
+			SELECT string_agg(prr.reviewer_id, ',' ORDER BY prr.reviewer_id)
+			FROM pull_request_reviewers prr
+			WHERE prr.pull_request_id = pr.pull_request_id AND prr.review_state = 'PENDING'
+		)
+	`
+
+	rows, err := tx.Query(ctx, query, fallback.Seconds())
+	if err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	var (
+		ids    []string
+		events []model.Event
+	)
 
 	for rows.Next() {
-		var s model.AssignmentStats
-		if err := rows.Scan(&s.UserID, &s.Count); err != nil {
+		var prID, authorID, pending string
+		if err := rows.Scan(&prID, &authorID, &pending); err != nil {
+			rows.Close()
+			_ = tx.Rollback(ctx)
 			return nil, fmt.Errorf("database error: %w", err)
 		}
 
-		stats = append(stats, s)
+		ids = append(ids, prID)
+		events = append(events, model.Event{
+			Type:          model.EventReviewEscalated,
+			PullRequestID: prID,
+			UserID:        authorID,
+			Payload:       map[string]string{"pending_reviewers": pending},
+		})
 	}
+	rows.Close()
 
 	if err := rows.Err(); err != nil {
+		_ = tx.Rollback(ctx)
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
-	return stats, nil
+	if err := event.Append(ctx, tx, events...); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return ids, nil
 }
 
-func (r *PullRequestRepository) getReviewers(ctx context.Context, prID string) ([]string, error) {
+// SnapshotAssignmentStats stores per reviewer assignment counts taken at takenAt.
+func (r *PullRequestRepository) SnapshotAssignmentStats(ctx context.Context, takenAt time.Time) (int, error) {
 	const query = `
-		SELECT reviewer_id
-		FROM pull_request_reviewers
-		WHERE pull_request_id = $1
-		ORDER BY reviewer_id
+		INSERT INTO assignment_stats_snapshots (taken_at, reviewer_id, total_count, open_count)
+		SELECT $1, prr.reviewer_id, COUNT(*), COUNT(*) FILTER (WHERE pr.status = 'OPEN')
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		GROUP BY prr.reviewer_id
 	`
 
-	rows, err := r.pool.Query(ctx, query, prID)
+	tag, err := r.db(ctx).Exec(ctx, query, takenAt)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// ListOverdueReviews returns pending reviews on open pull requests assigned longer ago than
+// the author team's SLA multiplied by factor. An empty teamName matches every team.
+func (r *PullRequestRepository) ListOverdueReviews(ctx context.Context, teamName string, factor float64) ([]model.OverdueReview, error) {
+	const query = `
+		SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, prr.reviewer_id,
+			t.team_name, prr.assigned_at, t.review_sla_minutes
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		JOIN users au ON au.user_id = pr.author_id
+		JOIN teams t ON t.team_name = au.team_name
+		WHERE pr.status = 'OPEN'
+			AND prr.review_state = 'PENDING'
+			AND t.review_sla_minutes > 0
+			AND ($1 = '' OR t.team_name = $1)
+			AND prr.assigned_at + make_interval(mins => t.review_sla_minutes) * $2::float8 <= NOW()
+		ORDER BY prr.assigned_at, pr.pull_request_id, prr.reviewer_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, teamName, factor)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
 	}
 	defer rows.Close()
 
-	var reviewers []string
+	var reviews []model.OverdueReview
 
 	for rows.Next() {
-		var reviewerID string
-		if err := rows.Scan(&reviewerID); err != nil {
-			return nil, err
+		var o model.OverdueReview
+		if err := rows.Scan(&o.PullRequestID, &o.PullRequestName, &o.AuthorID, &o.ReviewerID,
+			&o.TeamName, &o.AssignedAt, &o.ReviewSLAMinutes); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
 		}
 
-		reviewers = append(reviewers, reviewerID)
+		reviews = append(reviews, o)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, err
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return reviews, nil
+}
+
+// loadReviewers fills assigned reviewers and marks those who are not from the author's team.
+func (r *PullRequestRepository) loadReviewers(ctx context.Context, pr *model.PullRequest) error {
+	const query = `
+		SELECT prr.reviewer_id, ru.team_name <> au.team_name, prr.review_state, prr.assigned_at, prr.reviewed_at
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON prr.pull_request_id = pr.pull_request_id
+		JOIN users ru ON prr.reviewer_id = ru.user_id
+		JOIN users au ON pr.author_id = au.user_id
+		WHERE prr.pull_request_id = $1
+		ORDER BY prr.reviewer_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, pr.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			review    model.Review
+			crossTeam bool
+		)
+		if err := rows.Scan(&review.ReviewerID, &crossTeam, &review.State, &review.AssignedAt, &review.ReviewedAt); err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = append(pr.AssignedReviewers, review.ReviewerID)
+		pr.Reviews = append(pr.Reviews, review)
+		if crossTeam {
+			pr.CrossTeamReviewers = append(pr.CrossTeamReviewers, review.ReviewerID)
+		}
 	}
 
-	return reviewers, nil
+	return rows.Err()
 }
 
 type pullRequestScanner interface {
@@ -303,6 +691,7 @@ func scanPullRequest(row pullRequestScanner) (*model.PullRequest, error) {
 		&pr.Name,
 		&pr.AuthorID,
 		&pr.Status,
+		&pr.NeedsReviewers,
 		&pr.CreatedAt,
 		&pr.MergedAt,
 	); err != nil {
@@ -0,0 +1,193 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+// statsWhere filters pull requests (pr) by their author (au) with the args returned by statsArgs.
+const statsWhere = `
+	($1 = '' OR au.team_name = $1)
+	AND ($2::timestamptz IS NULL OR pr.created_at >= $2)
+	AND ($3::timestamptz IS NULL OR pr.created_at < $3)
+	AND ($4 = '' OR pr.status = $4)
+`
+
+func statsArgs(filter model.StatsFilter) []any {
+	return []any{filter.TeamName, filter.From, filter.To, string(filter.Status)}
+}
+
+func (r *PullRequestRepository) GetAssignmentStats(ctx context.Context, filter model.StatsFilter) ([]model.AssignmentStats, error) {
+	const query = `
+		SELECT prr.reviewer_id, COUNT(*) AS count,
+			COUNT(*) FILTER (WHERE pr.status = 'OPEN'),
+			COUNT(*) FILTER (WHERE pr.status = 'MERGED')
+		FROM pull_request_reviewers prr
+		JOIN pull_requests pr ON pr.pull_request_id = prr.pull_request_id
+		JOIN users au ON au.user_id = pr.author_id
+		WHERE ` + statsWhere + `
+		GROUP BY prr.reviewer_id
+		ORDER BY count DESC, prr.reviewer_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, statsArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []model.AssignmentStats
+
+	for rows.Next() {
+		var s model.AssignmentStats
+		if err := rows.Scan(&s.UserID, &s.Count, &s.OpenCount, &s.MergedCount); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		stats = append(stats, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return stats, nil
+}
+
+func (r *PullRequestRepository) CountPullRequests(ctx context.Context, filter model.StatsFilter) (int, error) {
+	const query = `
+		SELECT COUNT(*)
+		FROM pull_requests pr
+		JOIN users au ON au.user_id = pr.author_id
+		WHERE ` + statsWhere
+
+	var count int
+	if err := r.db(ctx).QueryRow(ctx, query, statsArgs(filter)...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return count, nil
+}
+
+// GetTimeToFirstReview measures from pull request creation to the first verdict of its reviewers.
+// It reads reviewed_at rather than the event log, which only has verdicts submitted since events exist.
+func (r *PullRequestRepository) GetTimeToFirstReview(ctx context.Context, filter model.StatsFilter) (model.DurationStats, error) {
+	const query = `
+		SELECT EXTRACT(EPOCH FROM MIN(prr.reviewed_at) - pr.created_at)::float8 / 60 AS minutes
+		FROM pull_requests pr
+		JOIN users au ON au.user_id = pr.author_id
+		JOIN pull_request_reviewers prr ON prr.pull_request_id = pr.pull_request_id AND prr.reviewed_at IS NOT NULL
+		WHERE ` + statsWhere + `
+		GROUP BY pr.pull_request_id, pr.created_at
+	`
+
+	return r.durationStats(ctx, query, filter)
+}
+
+func (r *PullRequestRepository) GetTimeToMerge(ctx context.Context, filter model.StatsFilter) (model.DurationStats, error) {
+	const query = `
+		SELECT EXTRACT(EPOCH FROM pr.merged_at - pr.created_at)::float8 / 60 AS minutes
+		FROM pull_requests pr
+		JOIN users au ON au.user_id = pr.author_id
+		WHERE ` + statsWhere + `
+			AND pr.status = 'MERGED' AND pr.merged_at IS NOT NULL
+	`
+
+	return r.durationStats(ctx, query, filter)
+}
+
+// GetReassignmentCounts returns how many times each reviewer was replaced on the filtered pull requests.
+func (r *PullRequestRepository) GetReassignmentCounts(ctx context.Context, filter model.StatsFilter) (map[string]int, error) {
+	const query = `
+		SELECT e.user_id, COUNT(*)
+		FROM events e
+		JOIN pull_requests pr ON pr.pull_request_id = e.pull_request_id
+		JOIN users au ON au.user_id = pr.author_id
+		WHERE e.event_type = 'REVIEWER_REASSIGNED' AND ` + statsWhere + `
+		GROUP BY e.user_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, statsArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+
+	for rows.Next() {
+		var (
+			userID string
+			count  int
+		)
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		counts[userID] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return counts, nil
+}
+
+// GetWeeklyLoad counts reviewer assignments, including later replaced ones, per ISO week.
+func (r *PullRequestRepository) GetWeeklyLoad(ctx context.Context, filter model.StatsFilter) ([]model.WeeklyLoad, error) {
+	const query = `
+		SELECT date_trunc('week', e.created_at) AS week, e.user_id, COUNT(*)
+		FROM events e
+		JOIN pull_requests pr ON pr.pull_request_id = e.pull_request_id
+		JOIN users au ON au.user_id = pr.author_id
+		WHERE e.event_type = 'REVIEWER_ASSIGNED' AND ` + statsWhere + `
+		GROUP BY week, e.user_id
+		ORDER BY week, e.user_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, statsArgs(filter)...)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var load []model.WeeklyLoad
+
+	for rows.Next() {
+		var l model.WeeklyLoad
+		if err := rows.Scan(&l.WeekStart, &l.UserID, &l.Count); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		load = append(load, l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return load, nil
+}
+
+// durationStats aggregates the single "minutes" column produced by query.
+func (r *PullRequestRepository) durationStats(ctx context.Context, query string, filter model.StatsFilter) (model.DurationStats, error) {
+	aggregate := `
+		SELECT COUNT(*),
+			COALESCE(AVG(minutes), 0),
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY minutes), 0),
+			COALESCE(percentile_cont(0.9) WITHIN GROUP (ORDER BY minutes), 0)
+		FROM (` + query + `) durations
+	`
+
+	var stats model.DurationStats
+	if err := r.db(ctx).QueryRow(ctx, aggregate, statsArgs(filter)...).Scan(
+		&stats.Count, &stats.AvgMinutes, &stats.P50Minutes, &stats.P90Minutes,
+	); err != nil {
+		return model.DurationStats{}, fmt.Errorf("database error: %w", err)
+	}
+
+	return stats, nil
+}
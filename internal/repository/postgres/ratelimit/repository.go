@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/db/postgres"
+)
+
+// BucketRepository keeps token buckets in Postgres so every replica draws from the same bucket.
+type BucketRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *BucketRepository {
+	return &BucketRepository{pool: pool}
+}
+
+func (r *BucketRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
+// Take refills the bucket by rate tokens per second up to burst and spends one token if there is one.
+// The database clock is used so replicas agree on elapsed time; concurrent requests for the same key
+// are serialized by the row lock.
+func (r *BucketRepository) Take(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	const query = `
+		WITH prev AS (
+			SELECT tokens, updated_at
+			FROM rate_limit_buckets
+			WHERE bucket_key = $1
+			FOR UPDATE
+		), refilled AS (
+			SELECT LEAST(
+				$2::DOUBLE PRECISION,
+				COALESCE((SELECT tokens + EXTRACT(EPOCH FROM NOW() - updated_at)::DOUBLE PRECISION * $3::DOUBLE PRECISION FROM prev), $2)
+			) AS tokens
+		)
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		SELECT $1, CASE WHEN tokens >= 1 THEN tokens - 1 ELSE tokens END, NOW()
+		FROM refilled
+		ON CONFLICT (bucket_key) DO UPDATE
+		SET tokens = EXCLUDED.tokens, updated_at = EXCLUDED.updated_at
+		RETURNING (SELECT tokens FROM refilled)
+	`
+
+	var tokens float64
+	if err := r.db(ctx).QueryRow(ctx, query, key, float64(burst), rate).Scan(&tokens); err != nil {
+		return false, 0, fmt.Errorf("database error: %w", err)
+	}
+
+	if tokens >= 1 {
+		return true, 0, nil
+	}
+
+	return false, time.Duration((1 - tokens) / rate * float64(time.Second)), nil
+}
+
+// DeleteIdle removes buckets untouched since before; they would be full again anyway.
+func (r *BucketRepository) DeleteIdle(ctx context.Context, before time.Time) (int64, error) {
+	const query = `
+		DELETE FROM rate_limit_buckets
+		WHERE updated_at < $1
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("database error: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"mor80/service-reviewer/internal/db/postgres"
 	"mor80/service-reviewer/internal/model"
 )
 
@@ -20,13 +21,17 @@ func New(pool *pgxpool.Pool) *TeamRepository {
 	return &TeamRepository{pool: pool}
 }
 
+func (r *TeamRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
 func (r *TeamRepository) Create(ctx context.Context, teamName string) error {
 	const query = `
 		INSERT INTO teams (team_name)
 		VALUES ($1)
 	`
 
-	if _, err := r.pool.Exec(ctx, query, teamName); err != nil {
+	if _, err := r.db(ctx).Exec(ctx, query, teamName); err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			return model.ErrTeamExists
@@ -46,7 +51,7 @@ func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, err
 	`
 
 	var exists int
-	if err := r.pool.QueryRow(ctx, query, teamName).Scan(&exists); err != nil {
+	if err := r.db(ctx).QueryRow(ctx, query, teamName).Scan(&exists); err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return false, nil
 		}
@@ -58,19 +63,9 @@ func (r *TeamRepository) Exists(ctx context.Context, teamName string) (bool, err
 }
 
 func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*model.Team, error) {
-	const queryTeam = `
-		SELECT team_name
-		FROM teams
-		WHERE team_name = $1
-	`
-
-	var name string
-	if err := r.pool.QueryRow(ctx, queryTeam, teamName).Scan(&name); err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, model.ErrNotFound
-		}
-
-		return nil, fmt.Errorf("database error: %w", err)
+	settings, err := r.GetSettings(ctx, teamName)
+	if err != nil {
+		return nil, err
 	}
 
 	const queryMembers = `
@@ -80,14 +75,18 @@ func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*model
 		ORDER BY user_id
 	`
 
-	rows, err := r.pool.Query(ctx, queryMembers, teamName)
+	rows, err := r.db(ctx).Query(ctx, queryMembers, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 	defer rows.Close()
 
 	team := &model.Team{
-		Name: name,
+		Name:         settings.TeamName,
+		MinReviewers: settings.MinReviewers,
+		MaxReviewers: settings.MaxReviewers,
+		BackupTeams:  settings.BackupTeams,
+		MergePolicy:  settings.MergePolicy,
 	}
 
 	for rows.Next() {
@@ -106,6 +105,191 @@ func (r *TeamRepository) GetByName(ctx context.Context, teamName string) (*model
 	return team, nil
 }
 
+func (r *TeamRepository) GetSettings(ctx context.Context, teamName string) (*model.TeamSettings, error) {
+	const query = `
+		SELECT team_name, min_reviewers, max_reviewers,
+			merge_min_approvals, merge_require_reviewer, merge_require_active_author,
+			review_sla_minutes
+		FROM teams
+		WHERE team_name = $1
+	`
+
+	settings, err := scanTeamSettings(r.db(ctx).QueryRow(ctx, query, teamName))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	backupTeams, err := r.getBackupTeams(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	settings.BackupTeams = backupTeams
+
+	return settings, nil
+}
+
+func (r *TeamRepository) UpdateSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error) {
+	const query = `
+		UPDATE teams
+		SET min_reviewers = $2, max_reviewers = $3
+		WHERE team_name = $1
+		RETURNING team_name, min_reviewers, max_reviewers,
+			merge_min_approvals, merge_require_reviewer, merge_require_active_author,
+			review_sla_minutes
+	`
+
+	updated, err := scanTeamSettings(r.db(ctx).QueryRow(ctx, query, settings.TeamName, settings.MinReviewers, settings.MaxReviewers))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	backupTeams, err := r.getBackupTeams(ctx, settings.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	updated.BackupTeams = backupTeams
+
+	return updated, nil
+}
+
+func (r *TeamRepository) SetReviewSLA(ctx context.Context, teamName string, minutes int) (*model.TeamSettings, error) {
+	const query = `
+		UPDATE teams
+		SET review_sla_minutes = $2
+		WHERE team_name = $1
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, teamName, minutes)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, model.ErrNotFound
+	}
+
+	return r.GetSettings(ctx, teamName)
+}
+
+func (r *TeamRepository) SetMergePolicy(ctx context.Context, teamName string, policy model.MergePolicy) (*model.TeamSettings, error) {
+	const query = `
+		UPDATE teams
+		SET merge_min_approvals = $2, merge_require_reviewer = $3, merge_require_active_author = $4
+		WHERE team_name = $1
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, teamName, policy.MinApprovals, policy.RequireReviewer, policy.RequireActiveAuthor)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return nil, model.ErrNotFound
+	}
+
+	return r.GetSettings(ctx, teamName)
+}
+
+func (r *TeamRepository) SetBackupTeams(ctx context.Context, teamName string, backupTeams []string) (*model.TeamSettings, error) {
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const lockQuery = `
+		SELECT 1
+		FROM teams
+		WHERE team_name = $1
+		FOR UPDATE
+	`
+
+	var exists int
+	if err := tx.QueryRow(ctx, lockQuery, teamName).Scan(&exists); err != nil {
+		_ = tx.Rollback(ctx)
+
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, model.ErrNotFound
+		}
+
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const deleteQuery = `
+		DELETE FROM team_backup_teams
+		WHERE team_name = $1
+	`
+
+	if _, err := tx.Exec(ctx, deleteQuery, teamName); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	const insertQuery = `
+		INSERT INTO team_backup_teams (team_name, backup_team_name, position)
+		VALUES ($1, $2, $3)
+	`
+
+	for i, backupTeam := range backupTeams {
+		if _, err := tx.Exec(ctx, insertQuery, teamName, backupTeam, i); err != nil {
+			_ = tx.Rollback(ctx)
+
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return nil, model.ErrNotFound
+			}
+
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return r.GetSettings(ctx, teamName)
+}
+
+func (r *TeamRepository) getBackupTeams(ctx context.Context, teamName string) ([]string, error) {
+	const query = `
+		SELECT backup_team_name
+		FROM team_backup_teams
+		WHERE team_name = $1
+		ORDER BY position
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, teamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var backupTeams []string
+
+	for rows.Next() {
+		var backupTeam string
+		if err := rows.Scan(&backupTeam); err != nil {
+			return nil, err
+		}
+
+		backupTeams = append(backupTeams, backupTeam)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return backupTeams, nil
+}
+
 type memberScanner interface {
 	Scan(dest ...any) error
 }
@@ -123,3 +307,111 @@ func scanTeamMember(row memberScanner) (model.TeamMember, error) {
 
 	return member, nil
 }
+
+func scanTeamSettings(row memberScanner) (*model.TeamSettings, error) {
+	var settings model.TeamSettings
+
+	if err := row.Scan(
+		&settings.TeamName,
+		&settings.MinReviewers,
+		&settings.MaxReviewers,
+		&settings.MergePolicy.MinApprovals,
+		&settings.MergePolicy.RequireReviewer,
+		&settings.MergePolicy.RequireActiveAuthor,
+		&settings.ReviewSLAMinutes,
+	); err != nil {
+		return nil, err
+	}
+
+	return &settings, nil
+}
+
+// SetRole grants or changes the user's role in the team.
+func (r *TeamRepository) SetRole(ctx context.Context, assignment model.TeamRoleAssignment) error {
+	const query = `
+		INSERT INTO team_roles (team_name, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (team_name, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+
+	if _, err := r.db(ctx).Exec(ctx, query, assignment.TeamName, assignment.UserID, assignment.Role); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return model.ErrNotFound
+		}
+
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TeamRepository) RemoveRole(ctx context.Context, teamName, userID string) error {
+	const query = `
+		DELETE FROM team_roles
+		WHERE team_name = $1 AND user_id = $2
+	`
+
+	tag, err := r.db(ctx).Exec(ctx, query, teamName, userID)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetRole returns the user's role in the team, or an empty role when the user has none.
+func (r *TeamRepository) GetRole(ctx context.Context, teamName, userID string) (model.TeamRole, error) {
+	const query = `
+		SELECT role
+		FROM team_roles
+		WHERE team_name = $1 AND user_id = $2
+	`
+
+	var role model.TeamRole
+	if err := r.db(ctx).QueryRow(ctx, query, teamName, userID).Scan(&role); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+
+		return "", fmt.Errorf("database error: %w", err)
+	}
+
+	return role, nil
+}
+
+func (r *TeamRepository) ListRoles(ctx context.Context, teamName string) ([]model.TeamRoleAssignment, error) {
+	const query = `
+		SELECT team_name, user_id, role
+		FROM team_roles
+		WHERE team_name = $1
+		ORDER BY role DESC, user_id
+	`
+
+	rows, err := r.db(ctx).Query(ctx, query, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var roles []model.TeamRoleAssignment
+
+	for rows.Next() {
+		var assignment model.TeamRoleAssignment
+		if err := rows.Scan(&assignment.TeamName, &assignment.UserID, &assignment.Role); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		roles = append(roles, assignment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return roles, nil
+}
@@ -4,11 +4,14 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"mor80/service-reviewer/internal/db/postgres"
 	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/repository/postgres/event"
 )
 
 type UserRepository struct {
@@ -19,6 +22,10 @@ func New(pool *pgxpool.Pool) *UserRepository {
 	return &UserRepository{pool: pool}
 }
 
+func (r *UserRepository) db(ctx context.Context) postgres.Querier {
+	return postgres.Conn(ctx, r.pool)
+}
+
 func (r *UserRepository) GetByID(ctx context.Context, userID string) (*model.User, error) {
 	const query = `
 		SELECT user_id, username, team_name, is_active
@@ -26,7 +33,7 @@ func (r *UserRepository) GetByID(ctx context.Context, userID string) (*model.Use
 		WHERE user_id = $1
 	`
 
-	row := r.pool.QueryRow(ctx, query, userID)
+	row := r.db(ctx).QueryRow(ctx, query, userID)
 
 	user, err := scanUser(row)
 	if err != nil {
@@ -48,7 +55,7 @@ func (r *UserRepository) ListByTeam(ctx context.Context, teamName string) ([]mod
 		ORDER BY user_id
 	`
 
-	rows, err := r.pool.Query(ctx, query, teamName)
+	rows, err := r.db(ctx).Query(ctx, query, teamName)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -86,7 +93,7 @@ func (r *UserRepository) Upsert(ctx context.Context, users []model.User) error {
 			is_active = EXCLUDED.is_active
 	`
 
-	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	tx, err := r.db(ctx).Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("database error: %w", err)
 	}
@@ -114,10 +121,15 @@ func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActiv
 		RETURNING user_id, username, team_name, is_active
 	`
 
-	row := r.pool.QueryRow(ctx, query, userID, isActive)
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
 
-	user, err := scanUser(row)
+	user, err := scanUser(tx.QueryRow(ctx, query, userID, isActive))
 	if err != nil {
+		_ = tx.Rollback(ctx)
+
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, model.ErrNotFound
 		}
@@ -125,6 +137,21 @@ func (r *UserRepository) SetIsActive(ctx context.Context, userID string, isActiv
 		return nil, fmt.Errorf("database error: %w", err)
 	}
 
+	eventType := model.EventUserDeactivated
+	if isActive {
+		eventType = model.EventUserActivated
+	}
+
+	if err := event.Append(ctx, tx, model.Event{Type: eventType, UserID: userID}); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
 	return user, nil
 }
 
@@ -139,7 +166,7 @@ func (r *UserRepository) ListByIDs(ctx context.Context, teamName string, userIDs
 		WHERE team_name = $1 AND user_id = ANY($2)
 	`
 
-	rows, err := r.pool.Query(ctx, query, teamName, userIDs)
+	rows, err := r.db(ctx).Query(ctx, query, teamName, userIDs)
 	if err != nil {
 		return nil, fmt.Errorf("database error: %w", err)
 	}
@@ -174,27 +201,67 @@ func (r *UserRepository) DeactivateUsers(ctx context.Context, teamName string, u
 		RETURNING user_id
 	`
 
-	rows, err := r.pool.Query(ctx, query, teamName, userIDs)
+	tx, err := r.db(ctx).Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	updated, err := collectIDs(tx.Query(ctx, query, teamName, userIDs))
 	if err != nil {
+		_ = tx.Rollback(ctx)
 		return nil, fmt.Errorf("database error: %w", err)
 	}
+
+	events := make([]model.Event, 0, len(updated)+1)
+	for _, id := range updated {
+		events = append(events, model.Event{
+			Type:    model.EventUserDeactivated,
+			UserID:  id,
+			Payload: map[string]string{"team_name": teamName},
+		})
+	}
+
+	if len(updated) > 0 {
+		events = append(events, model.Event{
+			Type:    model.EventTeamMembersDeactivated,
+			Payload: map[string]string{"team_name": teamName, "user_ids": strings.Join(updated, ",")},
+		})
+	}
+
+	if err := event.Append(ctx, tx, events...); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		_ = tx.Rollback(ctx)
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return updated, nil
+}
+
+func collectIDs(rows pgx.Rows, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
 	defer rows.Close()
 
-	var updated []string
+	var ids []string
 	for rows.Next() {
 		var id string
 		if err := rows.Scan(&id); err != nil {
-			return nil, fmt.Errorf("database error: %w", err)
+			return nil, err
 		}
 
-		updated = append(updated, id)
+		ids = append(ids, id)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("database error: %w", err)
+		return nil, err
 	}
 
-	return updated, nil
+	return ids, nil
 }
 
 type scanner interface {
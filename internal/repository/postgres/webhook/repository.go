@@ -0,0 +1,221 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func New(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	const query = `
+		INSERT INTO webhooks (url, secret, event_types, is_active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING webhook_id, url, secret, event_types, is_active, created_at
+	`
+
+	created, err := scanWebhook(r.pool.QueryRow(ctx, query, webhook.URL, webhook.Secret, eventTypesToStrings(webhook.EventTypes), webhook.IsActive))
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return created, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context) ([]model.Webhook, error) {
+	const query = `
+		SELECT webhook_id, url, secret, event_types, is_active, created_at
+		FROM webhooks
+		ORDER BY webhook_id
+	`
+
+	rows, err := r.pool.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var webhooks []model.Webhook
+
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		webhooks = append(webhooks, *webhook)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, webhookID int64) error {
+	const query = `
+		DELETE FROM webhooks
+		WHERE webhook_id = $1
+	`
+
+	tag, err := r.pool.Exec(ctx, query, webhookID)
+	if err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// ClaimDue locks due deliveries and pushes their next attempt by lease,
+// so other replicas skip them while this one is sending.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error) {
+	const query = `
+		WITH due AS (
+			SELECT delivery_id
+			FROM webhook_deliveries
+			WHERE status = 'PENDING' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE webhook_deliveries wd
+		SET next_attempt_at = NOW() + make_interval(secs => $2)
+		FROM due, webhooks w, events e
+		WHERE wd.delivery_id = due.delivery_id
+			AND w.webhook_id = wd.webhook_id
+			AND e.event_id = wd.event_id
+		RETURNING wd.delivery_id, wd.attempts,
+			w.webhook_id, w.url, w.secret,
+			e.event_id, e.event_type, COALESCE(e.pull_request_id, ''), COALESCE(e.user_id, ''), e.payload, e.created_at
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []model.WebhookDelivery
+
+	for rows.Next() {
+		var d model.WebhookDelivery
+		if err := rows.Scan(
+			&d.ID,
+			&d.Attempts,
+			&d.Webhook.ID,
+			&d.Webhook.URL,
+			&d.Webhook.Secret,
+			&d.Event.ID,
+			&d.Event.Type,
+			&d.Event.PullRequestID,
+			&d.Event.UserID,
+			&d.Event.Payload,
+			&d.Event.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("database error: %w", err)
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("database error: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) MarkDelivered(ctx context.Context, deliveryID int64, attempts int, deliveredAt time.Time) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'DELIVERED', attempts = $2, delivered_at = $3, last_error = NULL
+		WHERE delivery_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, deliveryID, attempts, deliveredAt); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) MarkRetry(ctx context.Context, deliveryID int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET attempts = $2, next_attempt_at = $3, last_error = $4
+		WHERE delivery_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, deliveryID, attempts, nextAttemptAt, lastError); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) MarkFailed(ctx context.Context, deliveryID int64, attempts int, lastError string) error {
+	const query = `
+		UPDATE webhook_deliveries
+		SET status = 'FAILED', attempts = $2, last_error = $3
+		WHERE delivery_id = $1
+	`
+
+	if _, err := r.pool.Exec(ctx, query, deliveryID, attempts, lastError); err != nil {
+		return fmt.Errorf("database error: %w", err)
+	}
+
+	return nil
+}
+
+type webhookScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanWebhook(row webhookScanner) (*model.Webhook, error) {
+	var (
+		webhook    model.Webhook
+		eventTypes []string
+	)
+
+	if err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&eventTypes,
+		&webhook.IsActive,
+		&webhook.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	for _, eventType := range eventTypes {
+		webhook.EventTypes = append(webhook.EventTypes, model.EventType(eventType))
+	}
+
+	return &webhook, nil
+}
+
+func eventTypesToStrings(eventTypes []model.EventType) []string {
+	result := make([]string, len(eventTypes))
+	for i, eventType := range eventTypes {
+		result[i] = string(eventType)
+	}
+
+	return result
+}
@@ -0,0 +1,178 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"go.opentelemetry.io/otel/attribute"
+
+	"mor80/service-reviewer/internal/tracing"
+)
+
+// tickTolerance absorbs the rounding of "@every" schedules, whose ticks are counted from each
+// replica's own start and shift slightly from run to run.
+const tickTolerance = 2 * time.Second
+
+// Locker makes sure a job runs on a single replica at a time.
+type Locker interface {
+	TryWithLock(ctx context.Context, key string, fn func(ctx context.Context) error) (bool, error)
+}
+
+// TickClaimer records handled ticks, so that a tick is run by one replica only even when the
+// replicas' timers fire at different moments.
+type TickClaimer interface {
+	ClaimTick(ctx context.Context, job string, tick time.Time, minGap time.Duration) (bool, error)
+}
+
+type Job struct {
+	Name     string
+	Schedule string
+	Run      func(ctx context.Context) error
+}
+
+type entry struct {
+	job      Job
+	schedule cron.Schedule
+}
+
+// Scheduler runs registered jobs on cron schedules ("*/5 * * * *", "@hourly", "@every 30s").
+type Scheduler struct {
+	locker  Locker
+	ticks   TickClaimer
+	logger  *slog.Logger
+	entries []entry
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New(locker Locker, ticks TickClaimer, logger *slog.Logger) *Scheduler {
+	return &Scheduler{
+		locker: locker,
+		ticks:  ticks,
+		logger: logger,
+	}
+}
+
+// Register adds a job. A job with an empty schedule is disabled and skipped.
+func (s *Scheduler) Register(job Job) error {
+	if job.Schedule == "" {
+		return nil
+	}
+
+	schedule, err := cron.ParseStandard(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("scheduler: job %s: %w", job.Name, err)
+	}
+
+	s.entries = append(s.entries, entry{job: job, schedule: schedule})
+	return nil
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, e := range s.entries {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+}
+
+// Stop stops scheduling new runs and waits for running jobs to finish.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, e entry) {
+	for {
+		next := e.schedule.Next(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		s.run(ctx, e.job, next, minGap(e.schedule))
+	}
+}
+
+// minGap is how long after a recorded run the next tick may run again. Cron ticks are wall clock
+// aligned and the same on every replica, so only the very same tick is skipped; "@every" ticks
+// differ per replica, so a whole delay has to pass since whichever replica ran last.
+func minGap(schedule cron.Schedule) time.Duration {
+	every, ok := schedule.(cron.ConstantDelaySchedule)
+	if !ok {
+		return 0
+	}
+
+	return max(every.Delay-tickTolerance, every.Delay/2)
+}
+
+func (s *Scheduler) run(ctx context.Context, job Job, tick time.Time, gap time.Duration) {
+	started := time.Now()
+
+	ctx, span := tracing.Start(ctx, "job "+job.Name, attribute.String("job.name", job.Name))
+	defer span.End()
+
+	// the tick is claimed under the lock: a replica that fails to lock must leave it to the one holding it
+	var claimed bool
+	ran, err := s.locker.TryWithLock(ctx, "job:"+job.Name, func(ctx context.Context) error {
+		var err error
+		if claimed, err = s.ticks.ClaimTick(ctx, job.Name, tick, gap); err != nil || !claimed {
+			return err
+		}
+
+		return runRecovered(ctx, job)
+	})
+	tracing.RecordError(span, err)
+	span.SetAttributes(attribute.Bool("job.ran", ran && claimed))
+	switch {
+	case err != nil && ctx.Err() == nil:
+		s.logger.Error("scheduled job failed", "job", job.Name, "err", err)
+	case !ran:
+		s.logger.Debug("scheduled job is running on another replica", "job", job.Name)
+	case !claimed:
+		s.logger.Debug("scheduled job tick handled by another replica", "job", job.Name)
+	case err == nil:
+		s.logger.Debug("scheduled job finished", "job", job.Name, "duration", time.Since(started))
+	}
+}
+
+// runRecovered turns a panic in a job into an error, so one broken job does not take the process down.
+func runRecovered(ctx context.Context, job Job) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v\n%s", p, debug.Stack())
+		}
+	}()
+
+	return job.Run(ctx)
+}
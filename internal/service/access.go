@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type authorizedKey struct{}
+
+// Authorized marks ctx as already checked by an outer operation, so the service calls it makes
+// on the caller's behalf (e.g. reassigning reviews on other teams' pull requests while deactivating
+// a team) skip their own team checks.
+func Authorized(ctx context.Context) context.Context {
+	return context.WithValue(ctx, authorizedKey{}, true)
+}
+
+func IsAuthorized(ctx context.Context) bool {
+	authorized, _ := ctx.Value(authorizedKey{}).(bool)
+	return authorized
+}
@@ -0,0 +1,86 @@
+package access
+
+import (
+	"context"
+	"fmt"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+type level int
+
+const (
+	levelMember level = iota + 1
+	levelMaintainer
+	levelOwner
+)
+
+// Checker authorizes the caller stored in ctx by the authentication middleware against team roles.
+// Admins always pass, and so do calls without a caller, which come from background jobs.
+type Checker struct {
+	teamRepo service.TeamRepository
+}
+
+func New(teamRepo service.TeamRepository) *Checker {
+	return &Checker{teamRepo: teamRepo}
+}
+
+func (c *Checker) OwnTeam(ctx context.Context, teamName string) error {
+	return c.authorize(ctx, teamName, levelOwner)
+}
+
+func (c *Checker) ManageTeam(ctx context.Context, teamName string) error {
+	return c.authorize(ctx, teamName, levelMaintainer)
+}
+
+func (c *Checker) ActInTeam(ctx context.Context, teamName string) error {
+	return c.authorize(ctx, teamName, levelMember)
+}
+
+func (c *Checker) authorize(ctx context.Context, teamName string, required level) error {
+	if service.IsAuthorized(ctx) {
+		return nil
+	}
+
+	principal, ok := model.PrincipalFrom(ctx)
+	if !ok || principal.Role == model.RoleAdmin {
+		return nil
+	}
+
+	if principal.UserID == "" {
+		return model.NewForbiddenError(teamName)
+	}
+
+	granted, err := c.level(ctx, principal, teamName)
+	if err != nil {
+		return err
+	}
+
+	if granted < required {
+		return model.NewForbiddenError(teamName)
+	}
+
+	return nil
+}
+
+// level combines the caller's explicit team role with membership: a lead manages their own team.
+func (c *Checker) level(ctx context.Context, principal model.Principal, teamName string) (level, error) {
+	role, err := c.teamRepo.GetRole(ctx, teamName, principal.UserID)
+	if err != nil {
+		return 0, fmt.Errorf("access: %w", err)
+	}
+
+	switch {
+	case role == model.TeamRoleOwner:
+		return levelOwner, nil
+	case role == model.TeamRoleMaintainer:
+		return levelMaintainer, nil
+	case principal.TeamName == teamName && principal.Role.Allows(model.RoleLead):
+		return levelMaintainer, nil
+	case principal.TeamName == teamName:
+		return levelMember, nil
+	default:
+		return 0, nil
+	}
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"golang.org/x/sync/singleflight"
+)
+
+// minRefreshInterval throttles reloads triggered by tokens signed with an unknown key id.
+const minRefreshInterval = time.Minute
+
+// JWKS holds the public keys used to verify JWTs, loaded from a local file or an HTTP URL.
+// Keys are reloaded lazily once refreshInterval has passed, or earlier when a token names
+// a key id that is not in the set, so rotated keys are picked up without a restart.
+type JWKS struct {
+	file            string
+	url             string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	// reloads collapses concurrent reloads into one fetch, made without holding mu
+	reloads singleflight.Group
+
+	mu   sync.RWMutex
+	keys jose.JSONWebKeySet
+	// checkedAt is the last reload attempt, successful or not, so a broken source is not hit on every request
+	checkedAt time.Time
+}
+
+// NewJWKS loads the key set once so that a misconfigured source fails at startup.
+func NewJWKS(ctx context.Context, file, url string, client *http.Client, refreshInterval time.Duration) (*JWKS, error) {
+	if (file == "") == (url == "") {
+		return nil, fmt.Errorf("exactly one of jwks file and jwks url must be set")
+	}
+
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	k := &JWKS{
+		file:            file,
+		url:             url,
+		client:          client,
+		refreshInterval: refreshInterval,
+	}
+
+	if err := k.reload(ctx); err != nil {
+		return nil, err
+	}
+
+	return k, nil
+}
+
+// Key returns the verification key with the given id. An empty kid matches only a set with a single key.
+func (k *JWKS) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	key, found, checkedAt := k.lookup(kid)
+
+	stale := k.refreshInterval > 0 && time.Since(checkedAt) > k.refreshInterval
+	if stale || (!found && time.Since(checkedAt) > minRefreshInterval) {
+		// keep serving the previous keys if the source is temporarily unavailable
+		if err := k.refresh(ctx, checkedAt); err == nil {
+			key, found, _ = k.lookup(kid)
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	return key, nil
+}
+
+// refresh reloads the keys unless someone has tried since seen. Callers arriving while a reload
+// is in flight wait for it instead of starting their own.
+func (k *JWKS) refresh(ctx context.Context, seen time.Time) error {
+	_, err, _ := k.reloads.Do("reload", func() (any, error) {
+		k.mu.RLock()
+		done := k.checkedAt.After(seen)
+		k.mu.RUnlock()
+
+		if done {
+			return nil, nil
+		}
+
+		// the fetch is shared, so one caller giving up must not fail the others
+		return nil, k.reload(context.WithoutCancel(ctx))
+	})
+
+	return err
+}
+
+// lookup also returns when the keys were last checked, read under the same lock as the keys.
+func (k *JWKS) lookup(kid string) (crypto.PublicKey, bool, time.Time) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	var candidates []jose.JSONWebKey
+	if kid == "" {
+		if len(k.keys.Keys) == 1 {
+			candidates = k.keys.Keys
+		}
+	} else {
+		candidates = k.keys.Key(kid)
+	}
+
+	for _, key := range candidates {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if key.IsPublic() {
+			return key.Key, true, k.checkedAt
+		}
+	}
+
+	return nil, false, k.checkedAt
+}
+
+// reload swaps in the fetched keys and records the attempt in one step, so a concurrent Key call
+// either sees the new keys or a check time old enough to wait for this reload.
+func (k *JWKS) reload(ctx context.Context) error {
+	keys, err := k.load(ctx)
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	k.checkedAt = time.Now()
+	if err != nil {
+		return err
+	}
+
+	k.keys = keys
+
+	return nil
+}
+
+func (k *JWKS) load(ctx context.Context) (jose.JSONWebKeySet, error) {
+	var keys jose.JSONWebKeySet
+
+	data, err := k.fetch(ctx)
+	if err != nil {
+		return keys, fmt.Errorf("load jwks: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return keys, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	if len(keys.Keys) == 0 {
+		return keys, fmt.Errorf("parse jwks: no keys")
+	}
+
+	return keys, nil
+}
+
+func (k *JWKS) fetch(ctx context.Context) ([]byte, error) {
+	if k.file != "" {
+		return os.ReadFile(k.file)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, k.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+// signingMethods are the accepted JWT algorithms; symmetric ones and "none" are rejected.
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+type JWTConfig struct {
+	Issuer   string
+	Audience string
+	// UserClaim holds the user id, "sub" by default.
+	UserClaim string
+	// RoleClaim holds a role name or a list of them; a dotted path reaches nested claims
+	// such as "realm_access.roles".
+	RoleClaim string
+	// RoleMapping translates SSO values (groups, realm roles) to service roles. It is the only way
+	// a token gets a role: a bare "admin" claim from the provider grants nothing.
+	RoleMapping map[string]model.Role
+	// DefaultRole is given to tokens without a recognised role; empty rejects them.
+	DefaultRole model.Role
+	Leeway      time.Duration
+}
+
+// JWTAuthenticator accepts JWTs signed by the SSO provider instead of the service's own tokens.
+type JWTAuthenticator struct {
+	keys     *JWKS
+	userRepo service.UserRepository
+	config   JWTConfig
+	parser   *jwt.Parser
+}
+
+func NewJWTAuthenticator(keys *JWKS, userRepo service.UserRepository, cfg JWTConfig) *JWTAuthenticator {
+	if cfg.UserClaim == "" {
+		cfg.UserClaim = "sub"
+	}
+
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "role"
+	}
+
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(cfg.Leeway),
+	}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return &JWTAuthenticator{
+		keys:     keys,
+		userRepo: userRepo,
+		config:   cfg,
+		parser:   jwt.NewParser(opts...),
+	}
+}
+
+// Authenticate verifies the token signature and standard claims and maps the claims to a caller.
+// The caller's team comes from the users table; SSO users unknown to the service act on no team and
+// deactivated users are rejected.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, rawToken string) (*model.Principal, error) {
+	if rawToken == "" {
+		return nil, model.ErrUnauthorized
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, model.ErrUnauthorized
+	}
+
+	userID, _ := claim(claims, a.config.UserClaim).(string)
+	if userID == "" {
+		return nil, model.ErrUnauthorized
+	}
+
+	role := a.role(claims)
+	if role == "" {
+		return nil, model.ErrUnauthorized
+	}
+
+	principal := &model.Principal{
+		UserID: userID,
+		Role:   role,
+	}
+
+	user, err := a.userRepo.GetByID(ctx, userID)
+	switch {
+	case err == nil && !user.IsActive:
+		return nil, model.ErrUnauthorized
+	case err == nil:
+		principal.TeamName = user.TeamName
+	case !errors.Is(err, model.ErrNotFound):
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	return principal, nil
+}
+
+// role picks the highest service role among the mapped claim values; unmapped values are ignored.
+func (a *JWTAuthenticator) role(claims jwt.MapClaims) model.Role {
+	var values []string
+
+	switch v := claim(claims, a.config.RoleClaim).(type) {
+	case string:
+		values = append(values, v)
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+	}
+
+	var best model.Role
+	for _, value := range values {
+		role, ok := a.config.RoleMapping[value]
+		if ok && role.Valid() && !best.Allows(role) {
+			best = role
+		}
+	}
+
+	if best == "" {
+		return a.config.DefaultRole
+	}
+
+	return best
+}
+
+// claim resolves a dotted path such as "realm_access.roles" in the token claims.
+func claim(claims jwt.MapClaims, path string) any {
+	var current any = map[string]any(claims)
+
+	for _, key := range strings.Split(path, ".") {
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		current = object[key]
+	}
+
+	return current
+}
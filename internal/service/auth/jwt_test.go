@@ -0,0 +1,312 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/golang-jwt/jwt/v5"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+const (
+	testIssuer   = "https://sso.example.com"
+	testAudience = "service-reviewer"
+)
+
+// fakeUsers knows only the users it is given; other repository methods are not used by the authenticator.
+type fakeUsers struct {
+	service.UserRepository
+	users map[string]model.User
+}
+
+func (r fakeUsers) GetByID(_ context.Context, userID string) (*model.User, error) {
+	user, ok := r.users[userID]
+	if !ok {
+		return nil, model.ErrNotFound
+	}
+
+	return &user, nil
+}
+
+type signingKey struct {
+	kid     string
+	private *ecdsa.PrivateKey
+}
+
+func newSigningKey(t *testing.T, kid string) signingKey {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	return signingKey{kid: kid, private: private}
+}
+
+func (k signingKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = k.kid
+
+	signed, err := token.SignedString(k.private)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+
+	return signed
+}
+
+// jwksServer publishes the public halves of its keys and counts how often the set is fetched.
+type jwksServer struct {
+	*httptest.Server
+
+	mu      sync.Mutex
+	keys    []signingKey
+	fetches int
+}
+
+func newJWKSServer(t *testing.T, keys ...signingKey) *jwksServer {
+	t.Helper()
+
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.fetches++
+
+		var set jose.JSONWebKeySet
+		for _, key := range s.keys {
+			set.Keys = append(set.Keys, jose.JSONWebKey{Key: &key.private.PublicKey, KeyID: key.kid, Algorithm: "ES256", Use: "sig"})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(s.Close)
+
+	return s
+}
+
+func (s *jwksServer) rotate(keys ...signingKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.keys = keys
+}
+
+func (s *jwksServer) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.fetches
+}
+
+func newTestAuthenticator(t *testing.T, srv *jwksServer, cfg JWTConfig) (*JWTAuthenticator, *JWKS) {
+	t.Helper()
+
+	keys, err := NewJWKS(context.Background(), "", srv.URL, srv.Client(), time.Hour)
+	if err != nil {
+		t.Fatalf("NewJWKS: %v", err)
+	}
+
+	cfg.Issuer = testIssuer
+	cfg.Audience = testAudience
+	users := fakeUsers{users: map[string]model.User{
+		"u1":      {ID: "u1", TeamName: "backend", IsActive: true},
+		"retired": {ID: "retired", TeamName: "backend"},
+	}}
+
+	return NewJWTAuthenticator(keys, users, cfg), keys
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":  testIssuer,
+		"aud":  testAudience,
+		"sub":  "u1",
+		"exp":  time.Now().Add(time.Hour).Unix(),
+		"role": "reviewers",
+	}
+}
+
+func withClaims(overrides jwt.MapClaims) jwt.MapClaims {
+	claims := validClaims()
+	for name, value := range overrides {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestJWTAuthenticatorAcceptsValidToken(t *testing.T) {
+	key := newSigningKey(t, "k1")
+	a, _ := newTestAuthenticator(t, newJWKSServer(t, key), JWTConfig{
+		RoleMapping: map[string]model.Role{"reviewers": model.RoleLead},
+	})
+
+	principal, err := a.Authenticate(context.Background(), key.sign(t, validClaims()))
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+
+	want := model.Principal{UserID: "u1", TeamName: "backend", Role: model.RoleLead}
+	if *principal != want {
+		t.Errorf("principal = %+v, want %+v", *principal, want)
+	}
+}
+
+func TestJWTAuthenticatorRejectsInvalidTokens(t *testing.T) {
+	key := newSigningKey(t, "k1")
+	a, _ := newTestAuthenticator(t, newJWKSServer(t, key), JWTConfig{DefaultRole: model.RoleMember})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"wrong issuer", key.sign(t, withClaims(jwt.MapClaims{"iss": "https://evil.example.com"}))},
+		{"wrong audience", key.sign(t, withClaims(jwt.MapClaims{"aud": "another-service"}))},
+		{"expired", key.sign(t, withClaims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}))},
+		{"no expiry", key.sign(t, withClaims(jwt.MapClaims{"exp": nil}))},
+		{"no subject", key.sign(t, withClaims(jwt.MapClaims{"sub": nil}))},
+		{"deactivated user", key.sign(t, withClaims(jwt.MapClaims{"sub": "retired"}))},
+		{"foreign key", newSigningKey(t, "k1").sign(t, validClaims())},
+		{"malformed", "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := a.Authenticate(context.Background(), tt.token); !errors.Is(err, model.ErrUnauthorized) {
+				t.Errorf("Authenticate error = %v, want ErrUnauthorized", err)
+			}
+		})
+	}
+}
+
+func TestJWTAuthenticatorRefreshesKeysForUnknownKid(t *testing.T) {
+	oldKey, newKey := newSigningKey(t, "k1"), newSigningKey(t, "k2")
+	srv := newJWKSServer(t, oldKey)
+	a, keys := newTestAuthenticator(t, srv, JWTConfig{DefaultRole: model.RoleMember})
+
+	srv.rotate(oldKey, newKey)
+	token := newKey.sign(t, validClaims())
+
+	// right after a reload unknown key ids do not hit the provider again
+	if _, err := a.Authenticate(context.Background(), token); !errors.Is(err, model.ErrUnauthorized) {
+		t.Fatalf("Authenticate error = %v, want ErrUnauthorized before the refresh is due", err)
+	}
+	if got := srv.fetchCount(); got != 1 {
+		t.Fatalf("fetches = %d, want 1", got)
+	}
+
+	keys.mu.Lock()
+	keys.checkedAt = time.Now().Add(-2 * minRefreshInterval)
+	keys.mu.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.Authenticate(context.Background(), token)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("Authenticate after rotation: %v", err)
+		}
+	}
+	if got := srv.fetchCount(); got != 2 {
+		t.Errorf("fetches = %d, want a single reload for concurrent requests", got)
+	}
+}
+
+func TestJWTAuthenticatorMapsRoles(t *testing.T) {
+	key := newSigningKey(t, "k1")
+
+	tests := []struct {
+		name        string
+		roleClaim   string
+		claims      jwt.MapClaims
+		defaultRole model.Role
+		want        model.Role
+		wantErr     bool
+	}{
+		{
+			name:   "mapped value",
+			claims: jwt.MapClaims{"role": "reviewer-admins"},
+			want:   model.RoleAdmin,
+		},
+		{
+			name:   "highest of several",
+			claims: jwt.MapClaims{"role": []any{"developers", "team-leads", "unrelated"}},
+			want:   model.RoleLead,
+		},
+		{
+			name:      "nested claim",
+			roleClaim: "realm_access.roles",
+			claims:    jwt.MapClaims{"role": nil, "realm_access": map[string]any{"roles": []any{"reviewer-admins"}}},
+			want:      model.RoleAdmin,
+		},
+		{
+			name:        "unmapped service role name",
+			claims:      jwt.MapClaims{"role": "admin"},
+			defaultRole: model.RoleMember,
+			want:        model.RoleMember,
+		},
+		{
+			name:    "unmapped without default",
+			claims:  jwt.MapClaims{"role": "admin"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := JWTConfig{
+				RoleMapping: map[string]model.Role{
+					"developers":      model.RoleMember,
+					"team-leads":      model.RoleLead,
+					"reviewer-admins": model.RoleAdmin,
+				},
+				RoleClaim:   tt.roleClaim,
+				DefaultRole: tt.defaultRole,
+			}
+			a, _ := newTestAuthenticator(t, newJWKSServer(t, key), cfg)
+
+			principal, err := a.Authenticate(context.Background(), key.sign(t, withClaims(tt.claims)))
+			if tt.wantErr {
+				if !errors.Is(err, model.ErrUnauthorized) {
+					t.Fatalf("Authenticate error = %v, want ErrUnauthorized", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+
+			if principal.Role != tt.want {
+				t.Errorf("role = %q, want %q", principal.Role, tt.want)
+			}
+		})
+	}
+}
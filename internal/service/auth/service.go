@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+const (
+	tokenPrefix = "rvw_"
+	tokenBytes  = 32
+
+	// touchInterval limits how often last_used_at is rewritten for a busy token.
+	touchInterval = time.Minute
+)
+
+type AuthService struct {
+	tokenRepo   service.APITokenRepository
+	userRepo    service.UserRepository
+	adminTokens [][sha256.Size]byte
+}
+
+// New builds the service; adminTokens are static tokens from the config that act as admin without a user.
+func New(tokenRepo service.APITokenRepository, userRepo service.UserRepository, adminTokens []string) *AuthService {
+	hashes := make([][sha256.Size]byte, 0, len(adminTokens))
+	for _, token := range adminTokens {
+		if token = strings.TrimSpace(token); token != "" {
+			hashes = append(hashes, sha256.Sum256([]byte(token)))
+		}
+	}
+
+	return &AuthService{
+		tokenRepo:   tokenRepo,
+		userRepo:    userRepo,
+		adminTokens: hashes,
+	}
+}
+
+// Authenticate resolves a bearer token to the caller. Unknown, revoked and expired tokens as well as
+// tokens of deactivated users all yield ErrUnauthorized.
+func (s *AuthService) Authenticate(ctx context.Context, rawToken string) (*model.Principal, error) {
+	if rawToken == "" {
+		return nil, model.ErrUnauthorized
+	}
+
+	hash := sha256.Sum256([]byte(rawToken))
+	for _, admin := range s.adminTokens {
+		if subtle.ConstantTimeCompare(hash[:], admin[:]) == 1 {
+			return &model.Principal{Role: model.RoleAdmin}, nil
+		}
+	}
+
+	token, err := s.tokenRepo.GetByHash(ctx, hex.EncodeToString(hash[:]))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			return nil, model.ErrUnauthorized
+		}
+
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if !token.ActiveAt(now) {
+		return nil, model.ErrUnauthorized
+	}
+
+	user, err := s.userRepo.GetByID(ctx, token.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	if !user.IsActive {
+		return nil, model.ErrUnauthorized
+	}
+
+	// last_used_at is informational, a busy token does not need a write on every request
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= touchInterval {
+		if err := s.tokenRepo.Touch(ctx, token.ID, now); err != nil {
+			return nil, fmt.Errorf("auth service: %w", err)
+		}
+	}
+
+	return &model.Principal{
+		UserID:   user.ID,
+		TeamName: user.TeamName,
+		Role:     token.Role,
+		TokenID:  token.ID,
+	}, nil
+}
+
+// IssueToken creates an API token for a user. The secret is returned only here, only its hash is stored.
+func (s *AuthService) IssueToken(ctx context.Context, token model.APIToken) (*model.APIToken, error) {
+	if err := validateToken(token); err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	secret, err := generateToken()
+	if err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	hash := sha256.Sum256([]byte(secret))
+	token.Name = strings.TrimSpace(token.Name)
+
+	created, err := s.tokenRepo.Create(ctx, token, hex.EncodeToString(hash[:]))
+	if err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	created.Token = secret
+
+	return created, nil
+}
+
+func (s *AuthService) ListTokens(ctx context.Context, userID string) ([]model.APIToken, error) {
+	if strings.TrimSpace(userID) == "" {
+		return nil, fmt.Errorf("auth service: user_id is required")
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	tokens, err := s.tokenRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("auth service: %w", err)
+	}
+
+	return tokens, nil
+}
+
+func (s *AuthService) RevokeToken(ctx context.Context, tokenID int64) error {
+	if tokenID <= 0 {
+		return fmt.Errorf("auth service: token_id is required")
+	}
+
+	if err := s.tokenRepo.Revoke(ctx, tokenID, time.Now().UTC()); err != nil {
+		return fmt.Errorf("auth service: %w", err)
+	}
+
+	return nil
+}
+
+func validateToken(token model.APIToken) error {
+	if strings.TrimSpace(token.UserID) == "" {
+		return fmt.Errorf("user_id is required")
+	}
+
+	if !token.Role.Valid() {
+		return fmt.Errorf("invalid role: %s", token.Role)
+	}
+
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+func generateToken() (string, error) {
+	buf := make([]byte, tokenBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return tokenPrefix + hex.EncodeToString(buf), nil
+}
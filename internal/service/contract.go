@@ -7,6 +7,31 @@ import (
 	"mor80/service-reviewer/internal/model"
 )
 
+// Transactor runs fn as one unit of work: repository calls made with the ctx passed to fn
+// commit or roll back together.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AccessChecker decides whether the caller in ctx may act on a team; it returns a FORBIDDEN domain error otherwise.
+type AccessChecker interface {
+	// OwnTeam allows team owners.
+	OwnTeam(ctx context.Context, teamName string) error
+	// ManageTeam allows owners, maintainers and leads who are members of the team.
+	ManageTeam(ctx context.Context, teamName string) error
+	// ActInTeam additionally allows every member of the team.
+	ActInTeam(ctx context.Context, teamName string) error
+}
+
+// DomainMetrics counts business events for monitoring.
+type DomainMetrics interface {
+	PullRequestCreated()
+	PullRequestMerged()
+	ReviewerReassigned()
+	NoCandidate()
+	UsersDeactivated(count int)
+}
+
 type UserRepository interface {
 	GetByID(ctx context.Context, userID string) (*model.User, error)
 	ListByTeam(ctx context.Context, teamName string) ([]model.User, error)
@@ -20,14 +45,74 @@ type TeamRepository interface {
 	Create(ctx context.Context, teamName string) error
 	Exists(ctx context.Context, teamName string) (bool, error)
 	GetByName(ctx context.Context, teamName string) (*model.Team, error)
+	GetSettings(ctx context.Context, teamName string) (*model.TeamSettings, error)
+	UpdateSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error)
+	SetReviewSLA(ctx context.Context, teamName string, minutes int) (*model.TeamSettings, error)
+	SetMergePolicy(ctx context.Context, teamName string, policy model.MergePolicy) (*model.TeamSettings, error)
+	SetBackupTeams(ctx context.Context, teamName string, backupTeams []string) (*model.TeamSettings, error)
+	SetRole(ctx context.Context, assignment model.TeamRoleAssignment) error
+	RemoveRole(ctx context.Context, teamName, userID string) error
+	GetRole(ctx context.Context, teamName, userID string) (model.TeamRole, error)
+	ListRoles(ctx context.Context, teamName string) ([]model.TeamRoleAssignment, error)
 }
 
 type PullRequestRepository interface {
 	Create(ctx context.Context, pr model.PullRequestDB, reviewerIDs []string) (*model.PullRequest, error)
 	GetByID(ctx context.Context, prID string) (*model.PullRequest, error)
-	UpdateStatus(ctx context.Context, prID string, status model.PullRequestStatus, mergedAt *time.Time) (*model.PullRequest, error)
+	UpdateStatus(ctx context.Context, prID string, from, status model.PullRequestStatus, mergedAt *time.Time) (*model.PullRequest, error)
 	ReplaceReviewer(ctx context.Context, prID, oldReviewerID, newReviewerID string) (*model.PullRequest, error)
+	AddReviewers(ctx context.Context, prID string, reviewerIDs []string, needsReviewers bool) (*model.PullRequest, error)
+	RemoveReviewer(ctx context.Context, prID, reviewerID string, needsReviewers bool) (*model.PullRequest, error)
+	SetReviewState(ctx context.Context, prID, reviewerID string, state model.ReviewState, reviewedAt time.Time) (*model.PullRequest, error)
 	ListByReviewer(ctx context.Context, reviewerID string) ([]model.PullRequestShort, error)
 	ListOpenAssignmentsByReviewers(ctx context.Context, reviewerIDs []string) ([]model.PullRequestAssignment, error)
-	GetAssignmentStats(ctx context.Context) ([]model.AssignmentStats, error)
+	ListOverdueReviews(ctx context.Context, teamName string, factor float64) ([]model.OverdueReview, error)
+	EscalateStaleReviews(ctx context.Context, fallback time.Duration) ([]string, error)
+	SnapshotAssignmentStats(ctx context.Context, takenAt time.Time) (int, error)
+	CountOpenAssignmentsByReviewers(ctx context.Context, reviewerIDs []string) (map[string]int, error)
+	GetAssignmentStats(ctx context.Context, filter model.StatsFilter) ([]model.AssignmentStats, error)
+	CountPullRequests(ctx context.Context, filter model.StatsFilter) (int, error)
+	GetTimeToFirstReview(ctx context.Context, filter model.StatsFilter) (model.DurationStats, error)
+	GetTimeToMerge(ctx context.Context, filter model.StatsFilter) (model.DurationStats, error)
+	GetReassignmentCounts(ctx context.Context, filter model.StatsFilter) (map[string]int, error)
+	GetWeeklyLoad(ctx context.Context, filter model.StatsFilter) ([]model.WeeklyLoad, error)
+}
+
+type EventRepository interface {
+	ListByPullRequest(ctx context.Context, prID string) ([]model.Event, error)
+	ListByUser(ctx context.Context, userID string) ([]model.Event, error)
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook model.Webhook) (*model.Webhook, error)
+	List(ctx context.Context) ([]model.Webhook, error)
+	Delete(ctx context.Context, webhookID int64) error
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]model.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, deliveryID int64, attempts int, deliveredAt time.Time) error
+	MarkRetry(ctx context.Context, deliveryID int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkFailed(ctx context.Context, deliveryID int64, attempts int, lastError string) error
+}
+
+type OutboxRepository interface {
+	ClaimBatch(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, outboxID int64, publishedAt time.Time) error
+	MarkFailed(ctx context.Context, outboxID int64, retryAt time.Time, lastError string) error
+}
+
+type AbsenceRepository interface {
+	Create(ctx context.Context, absence model.Absence) (*model.Absence, error)
+	ListByUser(ctx context.Context, userID string) ([]model.Absence, error)
+	GetByID(ctx context.Context, absenceID int64) (*model.Absence, error)
+	Delete(ctx context.Context, absenceID int64) error
+	ListUnavailable(ctx context.Context, userIDs []string) ([]string, error)
+	ListDueReassignments(ctx context.Context) ([]model.Absence, error)
+	MarkReassigned(ctx context.Context, absenceID int64, reassignedAt time.Time) error
+}
+
+type APITokenRepository interface {
+	Create(ctx context.Context, token model.APIToken, hash string) (*model.APIToken, error)
+	GetByHash(ctx context.Context, hash string) (*model.APIToken, error)
+	ListByUser(ctx context.Context, userID string) ([]model.APIToken, error)
+	Revoke(ctx context.Context, tokenID int64, revokedAt time.Time) error
+	Touch(ctx context.Context, tokenID int64, usedAt time.Time) error
 }
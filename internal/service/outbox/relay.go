@@ -0,0 +1,135 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	RetryDelay   time.Duration
+	Lease        time.Duration
+}
+
+// Relay publishes committed outbox messages to the configured sinks.
+// A message is marked published only after every sink accepted it, so delivery is at-least-once.
+type Relay struct {
+	repo   service.OutboxRepository
+	sinks  []Sink
+	logger *slog.Logger
+	config RelayConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRelay(repo service.OutboxRepository, sinks []Sink, logger *slog.Logger, cfg RelayConfig) *Relay {
+	return &Relay{
+		repo:   repo,
+		sinks:  sinks,
+		logger: logger,
+		config: cfg,
+	}
+}
+
+func (r *Relay) Start(ctx context.Context) {
+	if len(r.sinks) == 0 {
+		return
+	}
+
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("outbox relay failed", "err", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (r *Relay) Stop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+
+	r.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce relays up to BatchSize pending messages. They are claimed one at a time, so the lease
+// only has to cover publishing a single message and a slow sink cannot make later ones go out twice.
+func (r *Relay) RunOnce(ctx context.Context) error {
+	for range r.config.BatchSize {
+		messages, err := r.repo.ClaimBatch(ctx, 1, r.config.Lease)
+		if err != nil {
+			return fmt.Errorf("outbox relay: %w", err)
+		}
+
+		if len(messages) == 0 {
+			return nil
+		}
+
+		if err := r.relay(ctx, messages[0]); err != nil {
+			return fmt.Errorf("outbox relay: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) relay(ctx context.Context, msg model.OutboxMessage) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, msg); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	if len(errs) == 0 {
+		return r.repo.MarkPublished(ctx, msg.ID, time.Now().UTC())
+	}
+
+	publishErr := errors.Join(errs...)
+
+	r.logger.Warn("outbox publish attempt failed",
+		"outbox_id", msg.ID,
+		"event_type", msg.EventType,
+		"attempt", msg.Attempts+1,
+		"err", publishErr,
+	)
+
+	return r.repo.MarkFailed(ctx, msg.ID, time.Now().UTC().Add(r.config.RetryDelay), publishErr.Error())
+}
@@ -0,0 +1,180 @@
+package outbox
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service/webhook"
+)
+
+type messageResult struct {
+	id        int64
+	published bool
+	retryAt   time.Time
+	lastError string
+}
+
+// fakeRepo hands out queued messages and records what the relay did with them.
+type fakeRepo struct {
+	mu      sync.Mutex
+	queue   []model.OutboxMessage
+	claims  []int
+	results []messageResult
+}
+
+func (r *fakeRepo) ClaimBatch(_ context.Context, limit int, _ time.Duration) ([]model.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.claims = append(r.claims, limit)
+	n := min(limit, len(r.queue))
+	claimed := r.queue[:n]
+	r.queue = r.queue[n:]
+
+	return claimed, nil
+}
+
+func (r *fakeRepo) MarkPublished(_ context.Context, id int64, _ time.Time) error {
+	return r.record(messageResult{id: id, published: true})
+}
+
+func (r *fakeRepo) MarkFailed(_ context.Context, id int64, retryAt time.Time, lastError string) error {
+	return r.record(messageResult{id: id, retryAt: retryAt, lastError: lastError})
+}
+
+func (r *fakeRepo) record(result messageResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, result)
+	return nil
+}
+
+type failingSink struct{}
+
+func (failingSink) Name() string { return "failing" }
+
+func (failingSink) Publish(context.Context, model.OutboxMessage) error {
+	return errors.New("sink is down")
+}
+
+func newTestRelay(repo *fakeRepo, sinks []Sink, cfg RelayConfig) *Relay {
+	return NewRelay(repo, sinks, slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+}
+
+func testMessage(id int64) model.OutboxMessage {
+	return model.OutboxMessage{
+		ID:        id,
+		EventID:   id,
+		EventType: model.EventPullRequestCreated,
+		Payload:   json.RawMessage(`{"pull_request_id":"pr-` + strconv.FormatInt(id, 10) + `"}`),
+	}
+}
+
+func TestRelayPublishesToWebhookSink(t *testing.T) {
+	type received struct {
+		body    []byte
+		headers http.Header
+	}
+	got := make(chan received, 3)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{body: body, headers: r.Header.Clone()}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{queue: []model.OutboxMessage{testMessage(1), testMessage(2), testMessage(3)}}
+	relay := newTestRelay(repo, []Sink{NewWebhookSink(srv.URL, "s3cret", srv.Client())}, RelayConfig{BatchSize: 10, RetryDelay: time.Minute, Lease: time.Second})
+
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	for _, id := range []int64{1, 2, 3} {
+		req := <-got
+
+		if sig := req.headers.Get(webhook.HeaderSignature); !hmac.Equal([]byte(sig), []byte(webhook.Sign("s3cret", req.body))) {
+			t.Errorf("message %d: signature %q does not match body", id, sig)
+		}
+		if h := req.headers.Get(webhook.HeaderDelivery); h != strconv.FormatInt(id, 10) {
+			t.Errorf("delivery header = %q, want %d", h, id)
+		}
+		if string(req.body) != string(testMessage(id).Payload) {
+			t.Errorf("body = %s, want payload of message %d", req.body, id)
+		}
+	}
+
+	if len(repo.results) != 3 {
+		t.Fatalf("results = %+v, want 3 published", repo.results)
+	}
+	for _, res := range repo.results {
+		if !res.published {
+			t.Errorf("result = %+v, want published", res)
+		}
+	}
+
+	// messages are claimed one by one so each lease covers a single publish; the last claim finds none
+	if len(repo.claims) != 4 {
+		t.Errorf("claims = %v, want 4", repo.claims)
+	}
+	for _, limit := range repo.claims {
+		if limit != 1 {
+			t.Errorf("claimed %d messages at once, want 1", limit)
+		}
+	}
+}
+
+func TestRelayStopsAtBatchSize(t *testing.T) {
+	repo := &fakeRepo{queue: []model.OutboxMessage{testMessage(1), testMessage(2), testMessage(3)}}
+	relay := newTestRelay(repo, []Sink{NewLogSink(slog.New(slog.NewTextHandler(io.Discard, nil)))}, RelayConfig{BatchSize: 2, Lease: time.Second})
+
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	if len(repo.results) != 2 || len(repo.queue) != 1 {
+		t.Errorf("results = %+v, queue = %d, want 2 published and 1 left", repo.results, len(repo.queue))
+	}
+}
+
+func TestRelayRetriesFailedMessages(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{queue: []model.OutboxMessage{testMessage(1)}}
+	sinks := []Sink{NewWebhookSink(srv.URL, "s3cret", srv.Client()), failingSink{}}
+	relay := newTestRelay(repo, sinks, RelayConfig{BatchSize: 10, RetryDelay: time.Minute, Lease: time.Second})
+
+	started := time.Now().UTC()
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	if len(repo.results) != 1 {
+		t.Fatalf("results = %+v, want 1", repo.results)
+	}
+
+	// one failing sink keeps the message pending for every sink: delivery is at-least-once
+	res := repo.results[0]
+	if res.published || res.lastError == "" {
+		t.Errorf("result = %+v, want a failure with the sink error", res)
+	}
+	if delay := res.retryAt.Sub(started); delay < time.Minute || delay > time.Minute+5*time.Second {
+		t.Errorf("retry in %v, want about the retry delay", delay)
+	}
+}
@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service/webhook"
+)
+
+// Sink publishes outbox messages to an external system. Publish may be called
+// more than once for the same message, so consumers must deduplicate by ID.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, msg model.OutboxMessage) error
+}
+
+type LogSink struct {
+	logger *slog.Logger
+}
+
+func NewLogSink(logger *slog.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(_ context.Context, msg model.OutboxMessage) error {
+	s.logger.Info("domain event",
+		"outbox_id", msg.ID,
+		"event_id", msg.EventID,
+		"event_type", msg.EventType,
+		"payload", string(msg.Payload),
+	)
+
+	return nil
+}
+
+// FileSink appends messages to a file as JSON lines.
+type FileSink struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSink(path string) *FileSink {
+	return &FileSink{path: path}
+}
+
+func (s *FileSink) Name() string {
+	return "file"
+}
+
+func (s *FileSink) Publish(_ context.Context, msg model.OutboxMessage) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// WebhookSink posts every message to a single endpoint, signed the same way as webhook deliveries.
+type WebhookSink struct {
+	url    string
+	secret string
+	client *http.Client
+}
+
+func NewWebhookSink(url, secret string, client *http.Client) *WebhookSink {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return &WebhookSink{
+		url:    url,
+		secret: secret,
+		client: client,
+	}
+}
+
+func (s *WebhookSink) Name() string {
+	return "webhook"
+}
+
+func (s *WebhookSink) Publish(ctx context.Context, msg model.OutboxMessage) error {
+	return webhook.Post(ctx, s.client, s.url, s.secret, msg.EventType, msg.ID, msg.Payload)
+}
@@ -0,0 +1,278 @@
+package pullrequest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"mor80/service-reviewer/internal/service"
+)
+
+type Strategy string
+
+const (
+	StrategyRandom      Strategy = "random"
+	StrategyLeastLoaded Strategy = "least_loaded"
+	StrategyRoundRobin  Strategy = "round_robin"
+	StrategyWeighted    Strategy = "weighted"
+)
+
+// ReviewerSelector picks up to limit reviewers out of already filtered candidates.
+type ReviewerSelector interface {
+	Select(ctx context.Context, teamName string, candidates []string, limit int) ([]string, error)
+}
+
+type SelectorConfig struct {
+	Strategy       Strategy
+	TeamStrategies map[string]Strategy
+	Weights        map[string]int
+}
+
+// NewSelector builds a selector that dispatches to the strategy configured for the team,
+// falling back to the default strategy.
+func NewSelector(cfg SelectorConfig, prRepo service.PullRequestRepository, rng random) (ReviewerSelector, error) {
+	if rng == nil {
+		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	rng = &lockedRandom{random: rng}
+
+	if cfg.Strategy == "" {
+		cfg.Strategy = StrategyRandom
+	}
+
+	selectors := make(map[Strategy]ReviewerSelector)
+	build := func(strategy Strategy) (ReviewerSelector, error) {
+		if selector, ok := selectors[strategy]; ok {
+			return selector, nil
+		}
+
+		var selector ReviewerSelector
+		switch strategy {
+		case StrategyRandom:
+			selector = NewRandomSelector(rng)
+		case StrategyLeastLoaded:
+			selector = NewLeastLoadedSelector(prRepo, rng)
+		case StrategyRoundRobin:
+			selector = NewRoundRobinSelector()
+		case StrategyWeighted:
+			selector = NewWeightedSelector(rng, cfg.Weights)
+		default:
+			return nil, fmt.Errorf("unknown reviewer selection strategy: %s", strategy)
+		}
+
+		selectors[strategy] = selector
+		return selector, nil
+	}
+
+	fallback, err := build(cfg.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	teams := make(map[string]ReviewerSelector, len(cfg.TeamStrategies))
+	for teamName, strategy := range cfg.TeamStrategies {
+		selector, err := build(strategy)
+		if err != nil {
+			return nil, fmt.Errorf("team %s: %w", teamName, err)
+		}
+
+		teams[teamName] = selector
+	}
+
+	return &TeamSelector{
+		fallback: fallback,
+		teams:    teams,
+	}, nil
+}
+
+type TeamSelector struct {
+	fallback ReviewerSelector
+	teams    map[string]ReviewerSelector
+}
+
+func (s *TeamSelector) Select(ctx context.Context, teamName string, candidates []string, limit int) ([]string, error) {
+	if selector, ok := s.teams[teamName]; ok {
+		return selector.Select(ctx, teamName, candidates, limit)
+	}
+
+	return s.fallback.Select(ctx, teamName, candidates, limit)
+}
+
+type RandomSelector struct {
+	random random
+}
+
+func NewRandomSelector(rng random) *RandomSelector {
+	return &RandomSelector{random: rng}
+}
+
+func (s *RandomSelector) Select(_ context.Context, _ string, candidates []string, limit int) ([]string, error) {
+	return selectRandom(s.random, candidates, limit), nil
+}
+
+// LeastLoadedSelector prefers candidates with the fewest open reviews, breaking ties randomly.
+type LeastLoadedSelector struct {
+	prRepo service.PullRequestRepository
+	random random
+}
+
+func NewLeastLoadedSelector(prRepo service.PullRequestRepository, rng random) *LeastLoadedSelector {
+	return &LeastLoadedSelector{
+		prRepo: prRepo,
+		random: rng,
+	}
+}
+
+func (s *LeastLoadedSelector) Select(ctx context.Context, _ string, candidates []string, limit int) ([]string, error) {
+	if len(candidates) <= limit {
+		return append([]string(nil), candidates...), nil
+	}
+
+	load, err := s.prRepo.CountOpenAssignmentsByReviewers(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+
+	shuffled := shuffle(s.random, candidates)
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return load[shuffled[i]] < load[shuffled[j]]
+	})
+
+	return shuffled[:limit], nil
+}
+
+type RoundRobinSelector struct {
+	mu      sync.Mutex
+	cursors map[string]int
+}
+
+func NewRoundRobinSelector() *RoundRobinSelector {
+	return &RoundRobinSelector{cursors: make(map[string]int)}
+}
+
+// Select advances the team cursor right away so later picks in the same transaction move on,
+// and puts it back if the transaction rolls back, so dry runs do not change the next real pick.
+func (s *RoundRobinSelector) Select(ctx context.Context, teamName string, candidates []string, limit int) ([]string, error) {
+	if len(candidates) <= limit {
+		return append([]string(nil), candidates...), nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.cursors[teamName]
+	cursor := previous % len(candidates)
+	selected := make([]string, 0, limit)
+
+	for i := 0; i < limit; i++ {
+		selected = append(selected, candidates[(cursor+i)%len(candidates)])
+	}
+
+	next := (cursor + limit) % len(candidates)
+	s.cursors[teamName] = next
+
+	service.OnRollback(ctx, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		// a concurrent request has moved the cursor since, leave its pick in place
+		if s.cursors[teamName] == next {
+			s.cursors[teamName] = previous
+		}
+	})
+
+	return selected, nil
+}
+
+const defaultWeight = 1
+
+// WeightedSelector picks candidates with probability proportional to their weight; candidates with
+// weight 0 are only picked uniformly once nobody with a positive weight is left.
+type WeightedSelector struct {
+	random  random
+	weights map[string]int
+}
+
+func NewWeightedSelector(rng random, weights map[string]int) *WeightedSelector {
+	return &WeightedSelector{
+		random:  rng,
+		weights: weights,
+	}
+}
+
+func (s *WeightedSelector) Select(_ context.Context, _ string, candidates []string, limit int) ([]string, error) {
+	if len(candidates) <= limit {
+		return append([]string(nil), candidates...), nil
+	}
+
+	selected := make([]string, 0, limit)
+	remaining := append([]string(nil), candidates...)
+
+	for len(selected) < limit && len(remaining) > 0 {
+		total := 0
+		for _, id := range remaining {
+			total += s.weight(id)
+		}
+
+		// only zero weights are left: they still beat leaving the pull request without reviewers
+		if total == 0 {
+			selected = append(selected, selectRandom(s.random, remaining, limit-len(selected))...)
+			break
+		}
+
+		point := s.random.Intn(total)
+		idx := 0
+		for i, id := range remaining {
+			point -= s.weight(id)
+			if point < 0 {
+				idx = i
+				break
+			}
+		}
+
+		selected = append(selected, remaining[idx])
+		remaining = append(remaining[:idx], remaining[idx+1:]...)
+	}
+
+	return selected, nil
+}
+
+func (s *WeightedSelector) weight(userID string) int {
+	weight, ok := s.weights[userID]
+	if !ok {
+		return defaultWeight
+	}
+
+	if weight < 0 {
+		return 0
+	}
+
+	return weight
+}
+
+func shuffle(r random, ids []string) []string {
+	shuffled := append([]string(nil), ids...)
+
+	for i := len(shuffled) - 1; i > 0; i-- {
+		j := r.Intn(i + 1)
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	}
+
+	return shuffled
+}
+
+// lockedRandom makes a shared random source safe for concurrent requests.
+type lockedRandom struct {
+	mu     sync.Mutex
+	random random
+}
+
+func (r *lockedRandom) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.random.Intn(n)
+}
@@ -2,40 +2,72 @@ package pullrequest
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"mor80/service-reviewer/internal/model"
 	"mor80/service-reviewer/internal/service"
+	"mor80/service-reviewer/internal/tracing"
 )
 
-const maxReviewers = 2
-
 type random interface {
 	Intn(n int) int
 }
 
 type PullRequestService struct {
-	prRepo   service.PullRequestRepository
-	userRepo service.UserRepository
-	random   random
+	prRepo      service.PullRequestRepository
+	userRepo    service.UserRepository
+	teamRepo    service.TeamRepository
+	eventRepo   service.EventRepository
+	absenceRepo service.AbsenceRepository
+	selector    ReviewerSelector
+	tx          service.Transactor
+	metrics     service.DomainMetrics
+	access      service.AccessChecker
+
+	requiredApprovals int
 }
 
-func New(prRepo service.PullRequestRepository, userRepo service.UserRepository, rng random) *PullRequestService {
-	if rng == nil {
-		rng = rand.New(rand.NewSource(time.Now().UnixNano()))
+func New(
+	prRepo service.PullRequestRepository,
+	userRepo service.UserRepository,
+	teamRepo service.TeamRepository,
+	eventRepo service.EventRepository,
+	absenceRepo service.AbsenceRepository,
+	selector ReviewerSelector,
+	requiredApprovals int,
+	tx service.Transactor,
+	metrics service.DomainMetrics,
+	access service.AccessChecker,
+) *PullRequestService {
+	if selector == nil {
+		selector = NewRandomSelector(&lockedRandom{random: rand.New(rand.NewSource(time.Now().UnixNano()))})
 	}
 
 	return &PullRequestService{
-		prRepo:   prRepo,
-		userRepo: userRepo,
-		random:   rng,
+		prRepo:      prRepo,
+		userRepo:    userRepo,
+		teamRepo:    teamRepo,
+		eventRepo:   eventRepo,
+		absenceRepo: absenceRepo,
+		selector:    selector,
+		tx:          tx,
+		metrics:     metrics,
+		access:      access,
+
+		requiredApprovals: requiredApprovals,
 	}
 }
 
-func (s *PullRequestService) Create(ctx context.Context, pr model.PullRequest) (*model.PullRequest, error) {
+func (s *PullRequestService) Create(ctx context.Context, pr model.PullRequest) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Create", attribute.String("pull_request.id", pr.ID), attribute.String("author.id", pr.AuthorID))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateCreateInput(pr); err != nil {
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
@@ -45,33 +77,50 @@ func (s *PullRequestService) Create(ctx context.Context, pr model.PullRequest) (
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
 
-	teamMembers, err := s.userRepo.ListByTeam(ctx, author.TeamName)
-	if err != nil {
-		return nil, fmt.Errorf("pull request service: %w", err)
-	}
-
-	exclude := map[string]struct{}{author.ID: {}}
-	reviewerIDs := s.selectReviewers(teamMembers, exclude, maxReviewers)
-
 	now := time.Now().UTC()
 	prDB := model.PullRequestDB{
 		ID:        pr.ID,
 		Name:      pr.Name,
 		AuthorID:  pr.AuthorID,
-		Status:    model.PullRequestStatusOpen,
+		Status:    pr.Status,
 		CreatedAt: &now,
 		MergedAt:  nil,
 	}
 
+	var reviewerIDs []string
+
+	// drafts get their reviewers only when they are marked ready
+	if prDB.Status != model.PullRequestStatusDraft {
+		prDB.Status = model.PullRequestStatusOpen
+
+		settings, err := s.teamRepo.GetSettings(ctx, author.TeamName)
+		if err != nil {
+			return nil, fmt.Errorf("pull request service: %w", err)
+		}
+
+		exclude := map[string]struct{}{author.ID: {}}
+		reviewerIDs, err = s.pickReviewers(ctx, settings, exclude, settings.MaxReviewers)
+		if err != nil {
+			return nil, fmt.Errorf("pull request service: %w", err)
+		}
+
+		prDB.NeedsReviewers = len(reviewerIDs) < settings.MinReviewers
+	}
+
 	created, err := s.prRepo.Create(ctx, prDB, reviewerIDs)
 	if err != nil {
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
 
+	s.metrics.PullRequestCreated()
+
 	return created, nil
 }
 
-func (s *PullRequestService) Merge(ctx context.Context, prID string) (*model.PullRequest, error) {
+func (s *PullRequestService) Merge(ctx context.Context, prID string) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Merge", attribute.String("pull_request.id", prID))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validatePullRequestID(prID); err != nil {
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
@@ -81,12 +130,138 @@ func (s *PullRequestService) Merge(ctx context.Context, prID string) (*model.Pul
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
 
-	if pr.Status == model.PullRequestStatusMerged {
-		return pr, nil
+	if pr.Status == model.PullRequestStatusOpen {
+		if pr.Approvals() < s.requiredApprovals {
+			return nil, model.ErrNotApproved
+		}
+
+		if err := s.checkMergePolicy(ctx, pr); err != nil {
+			return nil, err
+		}
 	}
 
+	alreadyMerged := pr.Status == model.PullRequestStatusMerged
 	now := time.Now().UTC()
-	updated, err := s.prRepo.UpdateStatus(ctx, prID, model.PullRequestStatusMerged, &now)
+
+	pr, err = s.transition(ctx, prID, model.PullRequestStatusMerged, &now)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if !alreadyMerged {
+		s.metrics.PullRequestMerged()
+	}
+
+	return pr, nil
+}
+
+func (s *PullRequestService) Close(ctx context.Context, prID string) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Close", attribute.String("pull_request.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	pr, err := s.transition(ctx, prID, model.PullRequestStatusClosed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (s *PullRequestService) Reopen(ctx context.Context, prID string) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Reopen", attribute.String("pull_request.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.expectStatus(ctx, prID, model.PullRequestStatusClosed, model.PullRequestStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if current.Status == model.PullRequestStatusOpen {
+		return current, nil
+	}
+
+	var pr *model.PullRequest
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		reopened, err := s.transition(ctx, prID, model.PullRequestStatusOpen, nil)
+		if err != nil {
+			return err
+		}
+
+		pr, err = s.fillReviewers(ctx, reopened)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (s *PullRequestService) Ready(ctx context.Context, prID string) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Ready", attribute.String("pull_request.id", prID))
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.expectStatus(ctx, prID, model.PullRequestStatusDraft, model.PullRequestStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if current.Status == model.PullRequestStatusOpen {
+		return current, nil
+	}
+
+	var pr *model.PullRequest
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		opened, err := s.transition(ctx, prID, model.PullRequestStatusOpen, nil)
+		if err != nil {
+			return err
+		}
+
+		pr, err = s.assignReviewers(ctx, opened)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return pr, nil
+}
+
+func (s *PullRequestService) Review(ctx context.Context, prID, reviewerID string, state model.ReviewState) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Review", attribute.String("pull_request.id", prID), attribute.String("reviewer.id", reviewerID), attribute.String("review.state", string(state)))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validatePullRequestID(prID); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	reviewerID, err = reviewerFor(ctx, reviewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateUserID(reviewerID, "reviewer_id"); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if !state.Verdict() {
+		return nil, fmt.Errorf("pull request service: invalid review state: %s", state)
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if pr.Status == model.PullRequestStatusMerged {
+		return nil, model.ErrPRMerged
+	}
+
+	if pr.Status != model.PullRequestStatusOpen {
+		return nil, model.ErrPRNotOpen
+	}
+
+	updated, err := s.prRepo.SetReviewState(ctx, prID, reviewerID, state, time.Now().UTC())
 	if err != nil {
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
@@ -94,7 +269,29 @@ func (s *PullRequestService) Merge(ctx context.Context, prID string) (*model.Pul
 	return updated, nil
 }
 
-func (s *PullRequestService) Reassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error) {
+// PreviewReassign returns the pull request as Reassign would leave it, without persisting the change.
+func (s *PullRequestService) PreviewReassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error) {
+	var (
+		pr          *model.PullRequest
+		replacement string
+	)
+
+	err := service.DryRun(ctx, s.tx, func(ctx context.Context) error {
+		var err error
+		pr, replacement, err = s.Reassign(ctx, prID, oldReviewerID)
+		return err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return pr, replacement, nil
+}
+
+func (s *PullRequestService) Reassign(ctx context.Context, prID, oldReviewerID string) (_ *model.PullRequest, _ string, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.Reassign", attribute.String("pull_request.id", prID), attribute.String("reviewer.id", oldReviewerID), attribute.Bool("dry_run", service.IsDryRun(ctx)))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validatePullRequestID(prID); err != nil {
 		return nil, "", fmt.Errorf("pull request service: %w", err)
 	}
@@ -103,6 +300,10 @@ func (s *PullRequestService) Reassign(ctx context.Context, prID, oldReviewerID s
 		return nil, "", fmt.Errorf("pull request service: %w", err)
 	}
 
+	if model.EventReason(ctx) == "" {
+		ctx = model.WithEventReason(ctx, "manual reassignment")
+	}
+
 	pr, err := s.prRepo.GetByID(ctx, prID)
 	if err != nil {
 		return nil, "", fmt.Errorf("pull request service: %w", err)
@@ -112,38 +313,175 @@ func (s *PullRequestService) Reassign(ctx context.Context, prID, oldReviewerID s
 		return nil, "", model.ErrPRMerged
 	}
 
+	if pr.Status != model.PullRequestStatusOpen {
+		return nil, "", model.ErrPRNotOpen
+	}
+
 	if !containsReviewer(pr.AssignedReviewers, oldReviewerID) {
 		return nil, "", model.ErrNotAssigned
 	}
 
+	if err := s.authorizeReassign(ctx, pr, oldReviewerID); err != nil {
+		return nil, "", err
+	}
+
 	oldReviewer, err := s.userRepo.GetByID(ctx, oldReviewerID)
 	if err != nil {
 		return nil, "", fmt.Errorf("pull request service: %w", err)
 	}
 
-	members, err := s.userRepo.ListByTeam(ctx, oldReviewer.TeamName)
+	settings, err := s.teamRepo.GetSettings(ctx, oldReviewer.TeamName)
 	if err != nil {
 		return nil, "", fmt.Errorf("pull request service: %w", err)
 	}
 
 	exclude := newReviewers(pr.AuthorID, oldReviewerID, pr.AssignedReviewers)
-	candidates := filterMembers(members, exclude)
-	if len(candidates) == 0 {
+	selected, err := s.pickReviewers(ctx, settings, exclude, 1)
+	if err != nil {
+		return nil, "", fmt.Errorf("pull request service: %w", err)
+	}
+	if len(selected) == 0 {
+		// the error rolls back the caller's savepoint along with any after-commit hook, so the miss is
+		// counted right away: it happened whatever becomes of the transaction
+		if !service.IsDryRun(ctx) {
+			s.metrics.NoCandidate()
+		}
 		return nil, "", model.ErrNoCandidate
 	}
 
-	replacement := candidates[s.random.Intn(len(candidates))]
+	replacement := selected[0]
 
 	updated, err := s.prRepo.ReplaceReviewer(ctx, prID, oldReviewerID, replacement)
 	if err != nil {
 		return nil, "", fmt.Errorf("pull request service: %w", err)
 	}
 
+	updated, err = s.fillReviewers(ctx, updated)
+	if err != nil {
+		return nil, "", fmt.Errorf("pull request service: %w", err)
+	}
+
+	s.countAfterCommit(ctx, s.metrics.ReviewerReassigned)
+
 	return updated, replacement, nil
 }
 
-func (s *PullRequestService) AssignmentStats(ctx context.Context) ([]model.AssignmentStats, error) {
-	stats, err := s.prRepo.GetAssignmentStats(ctx)
+// reviewerFor resolves who submits a verdict: callers review as themselves, so an approval cannot be
+// recorded on behalf of another reviewer. Only admins may name a reviewer other than themselves.
+func reviewerFor(ctx context.Context, reviewerID string) (string, error) {
+	principal, ok := model.PrincipalFrom(ctx)
+	if !ok || service.IsAuthorized(ctx) || principal.Role.Allows(model.RoleAdmin) {
+		if reviewerID == "" {
+			return principal.UserID, nil
+		}
+		return reviewerID, nil
+	}
+
+	if reviewerID != "" && reviewerID != principal.UserID {
+		return "", model.NewDomainError(model.ErrorCodeForbidden, "verdicts can only be submitted by the reviewer")
+	}
+
+	return principal.UserID, nil
+}
+
+// countAfterCommit records a metric once the caller's transaction commits: reassignments inside a
+// deactivation that fails later, dry runs and previews are rolled back and must not be counted.
+func (s *PullRequestService) countAfterCommit(ctx context.Context, count func()) {
+	if service.IsDryRun(ctx) {
+		return
+	}
+
+	service.AfterCommit(ctx, count)
+}
+
+// authorizeReassign lets reviewers hand over their own reviews; anyone else has to act in the author's team.
+func (s *PullRequestService) authorizeReassign(ctx context.Context, pr *model.PullRequest, reviewerID string) error {
+	if principal, ok := model.PrincipalFrom(ctx); ok && principal.UserID != "" && principal.UserID == reviewerID {
+		return nil
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("pull request service: %w", err)
+	}
+
+	return s.access.ActInTeam(ctx, author.TeamName)
+}
+
+// DropReviewer unassigns a reviewer without a replacement and flags the pull request
+// if it falls below its team's minimum reviewers.
+func (s *PullRequestService) DropReviewer(ctx context.Context, prID, reviewerID string) (_ *model.PullRequest, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.DropReviewer", attribute.String("pull_request.id", prID), attribute.String("reviewer.id", reviewerID))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validatePullRequestID(prID); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if err := validateUserID(reviewerID, "reviewer_id"); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if pr.Status == model.PullRequestStatusMerged {
+		return nil, model.ErrPRMerged
+	}
+
+	if pr.Status != model.PullRequestStatusOpen {
+		return nil, model.ErrPRNotOpen
+	}
+
+	if !containsReviewer(pr.AssignedReviewers, reviewerID) {
+		return nil, model.ErrNotAssigned
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	needsReviewers := len(pr.AssignedReviewers)-1 < settings.MinReviewers
+
+	updated, err := s.prRepo.RemoveReviewer(ctx, prID, reviewerID, needsReviewers)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *PullRequestService) History(ctx context.Context, prID string) ([]model.Event, error) {
+	if err := validatePullRequestID(prID); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if _, err := s.prRepo.GetByID(ctx, prID); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	events, err := s.eventRepo.ListByPullRequest(ctx, prID)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return events, nil
+}
+
+func (s *PullRequestService) AssignmentStats(ctx context.Context, filter model.StatsFilter) ([]model.AssignmentStats, error) {
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	stats, err := s.prRepo.GetAssignmentStats(ctx, filter)
 	if err != nil {
 		return nil, fmt.Errorf("pull request service: %w", err)
 	}
@@ -151,13 +489,317 @@ func (s *PullRequestService) AssignmentStats(ctx context.Context) ([]model.Assig
 	return stats, nil
 }
 
-func (s *PullRequestService) selectReviewers(members []model.User, exclude map[string]struct{}, limit int) []string {
-	candidates := filterMembers(members, exclude)
+func (s *PullRequestService) StatsSummary(ctx context.Context, filter model.StatsFilter) (*model.ReviewStatsSummary, error) {
+	if err := validateStatsFilter(filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	var (
+		summary model.ReviewStatsSummary
+		err     error
+	)
+
+	if summary.PullRequests, err = s.prRepo.CountPullRequests(ctx, filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if summary.TimeToFirstReview, err = s.prRepo.GetTimeToFirstReview(ctx, filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if summary.TimeToMerge, err = s.prRepo.GetTimeToMerge(ctx, filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if summary.ReassignmentsByReviewer, err = s.prRepo.GetReassignmentCounts(ctx, filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	for _, count := range summary.ReassignmentsByReviewer {
+		summary.Reassignments += count
+	}
+
+	if summary.WeeklyLoad, err = s.prRepo.GetWeeklyLoad(ctx, filter); err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	if summary.WeeklyLoad == nil {
+		summary.WeeklyLoad = []model.WeeklyLoad{}
+	}
+
+	return &summary, nil
+}
+
+// EscalateStaleReviews raises a REVIEW_ESCALATED event for open pull requests with a review past its
+// team's SLA, the reviews OverdueReviews lists. Teams without an SLA escalate reviews pending longer
+// than after. It returns how many pull requests were escalated.
+func (s *PullRequestService) EscalateStaleReviews(ctx context.Context, after time.Duration) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.EscalateStaleReviews")
+	defer func() { tracing.End(span, err) }()
+
+	ids, err := s.prRepo.EscalateStaleReviews(ctx, after)
+	if err != nil {
+		return 0, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return len(ids), nil
+}
+
+// OverdueReviews lists pending reviews that exceeded their team's SLA. An empty teamName lists every team.
+func (s *PullRequestService) OverdueReviews(ctx context.Context, teamName string) ([]model.OverdueReview, error) {
+	reviews, err := s.prRepo.ListOverdueReviews(ctx, teamName, 1)
+	if err != nil {
+		return nil, fmt.Errorf("pull request service: %w", err)
+	}
+
+	now := time.Now()
+	for i := range reviews {
+		deadline := reviews[i].AssignedAt.Add(time.Duration(reviews[i].ReviewSLAMinutes) * time.Minute)
+		reviews[i].OverdueMinutes = int(now.Sub(deadline).Minutes())
+	}
+
+	return reviews, nil
+}
+
+// ReassignOverdueReviews reassigns pending reviews that exceeded their team's SLA by factor.
+// Reviews without a replacement candidate keep their reviewer. It returns how many reviews were reassigned.
+func (s *PullRequestService) ReassignOverdueReviews(ctx context.Context, factor float64) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.ReassignOverdueReviews")
+	defer func() { tracing.End(span, err) }()
+
+	reviews, err := s.prRepo.ListOverdueReviews(ctx, "", factor)
+	if err != nil {
+		return 0, fmt.Errorf("pull request service: %w", err)
+	}
+
+	ctx = model.WithEventReason(ctx, "review SLA exceeded")
+	reassigned := 0
+
+	for _, review := range reviews {
+		err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+			_, _, err := s.Reassign(ctx, review.PullRequestID, review.ReviewerID)
+			return err
+		})
+
+		var domainErr model.DomainError
+		switch {
+		case err == nil:
+			reassigned++
+		case errors.As(err, &domainErr):
+		default:
+			return reassigned, err
+		}
+	}
+
+	return reassigned, nil
+}
+
+func (s *PullRequestService) SnapshotAssignmentStats(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "PullRequestService.SnapshotAssignmentStats")
+	defer func() { tracing.End(span, err) }()
+
+	count, err := s.prRepo.SnapshotAssignmentStats(ctx, time.Now().UTC())
+	if err != nil {
+		return 0, fmt.Errorf("pull request service: %w", err)
+	}
+
+	return count, nil
+}
+
+// checkMergePolicy returns a merge blocked error listing every unmet condition of the author's team policy.
+func (s *PullRequestService) checkMergePolicy(ctx context.Context, pr *model.PullRequest) error {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("pull request service: %w", err)
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return fmt.Errorf("pull request service: %w", err)
+	}
+
+	policy := settings.MergePolicy
+	var unmet []string
+
+	if approvals := pr.Approvals(); approvals < policy.MinApprovals {
+		unmet = append(unmet, fmt.Sprintf("requires %d approvals, has %d", policy.MinApprovals, approvals))
+	}
+
+	if policy.RequireReviewer && len(pr.AssignedReviewers) == 0 {
+		unmet = append(unmet, "requires at least one assigned reviewer")
+	}
+
+	if policy.RequireActiveAuthor && !author.IsActive {
+		unmet = append(unmet, "author is not active")
+	}
+
+	if len(unmet) > 0 {
+		return model.NewMergeBlockedError(unmet)
+	}
+
+	return nil
+}
+
+// transition moves a pull request to the target status if the lifecycle allows it.
+// Moving to the status the pull request already has is a no-op.
+func (s *PullRequestService) transition(ctx context.Context, prID string, to model.PullRequestStatus, mergedAt *time.Time) (*model.PullRequest, error) {
+	if err := validatePullRequestID(prID); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status == to {
+		return pr, nil
+	}
+
+	if !pr.Status.CanTransitionTo(to) {
+		return nil, model.NewInvalidTransitionError(pr.Status, to)
+	}
+
+	// the update only applies while the status is still the one checked above
+	return s.prRepo.UpdateStatus(ctx, prID, pr.Status, to, mergedAt)
+}
+
+// expectStatus makes sure a pull request is either in the source status or already in the target one.
+func (s *PullRequestService) expectStatus(ctx context.Context, prID string, from, to model.PullRequestStatus) (*model.PullRequest, error) {
+	if err := validatePullRequestID(prID); err != nil {
+		return nil, err
+	}
+
+	pr, err := s.prRepo.GetByID(ctx, prID)
+	if err != nil {
+		return nil, err
+	}
+
+	if pr.Status != from && pr.Status != to {
+		return nil, model.NewInvalidTransitionError(pr.Status, to)
+	}
+
+	return pr, nil
+}
+
+// fillReviewers tops up a pull request that is still short of its team's minimum reviewers.
+func (s *PullRequestService) fillReviewers(ctx context.Context, pr *model.PullRequest) (*model.PullRequest, error) {
+	if len(pr.AssignedReviewers) == 0 {
+		return s.assignReviewers(ctx, pr)
+	}
+
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	if !pr.NeedsReviewers && len(pr.AssignedReviewers) >= settings.MinReviewers {
+		return pr, nil
+	}
+
+	return s.addReviewers(ctx, pr, author, settings)
+}
+
+// assignReviewers adds reviewers up to the team's maximum.
+func (s *PullRequestService) assignReviewers(ctx context.Context, pr *model.PullRequest) (*model.PullRequest, error) {
+	author, err := s.userRepo.GetByID(ctx, pr.AuthorID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teamRepo.GetSettings(ctx, author.TeamName)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.addReviewers(ctx, pr, author, settings)
+}
+
+func (s *PullRequestService) addReviewers(ctx context.Context, pr *model.PullRequest, author *model.User, settings *model.TeamSettings) (*model.PullRequest, error) {
+	var added []string
+
+	if missing := settings.MaxReviewers - len(pr.AssignedReviewers); missing > 0 {
+		exclude := newReviewers(author.ID, author.ID, pr.AssignedReviewers)
+
+		var err error
+		added, err = s.pickReviewers(ctx, settings, exclude, missing)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	needsReviewers := len(pr.AssignedReviewers)+len(added) < settings.MinReviewers
+	if len(added) == 0 && needsReviewers == pr.NeedsReviewers {
+		return pr, nil
+	}
+
+	return s.prRepo.AddReviewers(ctx, pr.ID, added, needsReviewers)
+}
+
+// pickReviewers selects reviewers from the team first and then from its backup teams in order.
+func (s *PullRequestService) pickReviewers(ctx context.Context, settings *model.TeamSettings, exclude map[string]struct{}, limit int) ([]string, error) {
+	skip := make(map[string]struct{}, len(exclude))
+	for id := range exclude {
+		skip[id] = struct{}{}
+	}
+
+	teams := append([]string{settings.TeamName}, settings.BackupTeams...)
+	var selected []string
+
+	for _, teamName := range teams {
+		if len(selected) >= limit {
+			break
+		}
+
+		members, err := s.userRepo.ListByTeam(ctx, teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		picked, err := s.selectReviewers(ctx, teamName, members, skip, limit-len(selected))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, id := range picked {
+			skip[id] = struct{}{}
+		}
+
+		selected = append(selected, picked...)
+	}
+
+	return selected, nil
+}
+
+func (s *PullRequestService) selectReviewers(ctx context.Context, teamName string, members []model.User, exclude map[string]struct{}, limit int) ([]string, error) {
+	unavailable, err := s.unavailable(ctx, members)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := filterMembers(members, exclude, unavailable)
 	if len(candidates) <= limit {
-		return candidates
+		return candidates, nil
 	}
 
-	return selectRandom(s.random, candidates, limit)
+	return s.selector.Select(ctx, teamName, candidates, limit)
+}
+
+func validateStatsFilter(filter model.StatsFilter) error {
+	if filter.Status != "" && !filter.Status.Valid() {
+		return fmt.Errorf("unknown status: %s", filter.Status)
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.To.After(*filter.From) {
+		return fmt.Errorf("to must be after from")
+	}
+
+	return nil
 }
 
 func validateCreateInput(pr model.PullRequest) error {
@@ -169,6 +811,10 @@ func validateCreateInput(pr model.PullRequest) error {
 		return fmt.Errorf("pull_request_name is required")
 	}
 
+	if pr.Status != "" && pr.Status != model.PullRequestStatusOpen && pr.Status != model.PullRequestStatusDraft {
+		return fmt.Errorf("pull request can only be created as %s or %s", model.PullRequestStatusOpen, model.PullRequestStatusDraft)
+	}
+
 	if err := validateUserID(pr.AuthorID, "author_id"); err != nil {
 		return err
 	}
@@ -215,7 +861,33 @@ func newReviewers(authorID, oldReviewerID string, assigned []string) map[string]
 	return reviewers
 }
 
-func filterMembers(members []model.User, exclude map[string]struct{}) []string {
+// unavailable returns the members who are absent right now.
+func (s *PullRequestService) unavailable(ctx context.Context, members []model.User) (map[string]struct{}, error) {
+	ids := make([]string, 0, len(members))
+	for _, member := range members {
+		if member.IsActive {
+			ids = append(ids, member.ID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	absent, err := s.absenceRepo.ListUnavailable(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	set := make(map[string]struct{}, len(absent))
+	for _, id := range absent {
+		set[id] = struct{}{}
+	}
+
+	return set, nil
+}
+
+func filterMembers(members []model.User, exclude, unavailable map[string]struct{}) []string {
 	var ids []string
 
 	for _, member := range members {
@@ -227,6 +899,10 @@ func filterMembers(members []model.User, exclude map[string]struct{}) []string {
 			continue
 		}
 
+		if _, absent := unavailable[member.ID]; absent {
+			continue
+		}
+
 		ids = append(ids, member.ID)
 	}
 
@@ -2,15 +2,20 @@ package team
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
 	"mor80/service-reviewer/internal/model"
 	"mor80/service-reviewer/internal/service"
+	"mor80/service-reviewer/internal/tracing"
 )
 
 type pullRequestService interface {
 	Reassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error)
+	DropReviewer(ctx context.Context, prID, reviewerID string) (*model.PullRequest, error)
 }
 
 type TeamService struct {
@@ -18,18 +23,35 @@ type TeamService struct {
 	userRepo service.UserRepository
 	prRepo   service.PullRequestRepository
 	prSvc    pullRequestService
+	tx       service.Transactor
+	metrics  service.DomainMetrics
+	access   service.AccessChecker
 }
 
-func New(teamRepo service.TeamRepository, userRepo service.UserRepository, prRepo service.PullRequestRepository, prSvc pullRequestService) *TeamService {
+func New(
+	teamRepo service.TeamRepository,
+	userRepo service.UserRepository,
+	prRepo service.PullRequestRepository,
+	prSvc pullRequestService,
+	tx service.Transactor,
+	metrics service.DomainMetrics,
+	access service.AccessChecker,
+) *TeamService {
 	return &TeamService{
 		teamRepo: teamRepo,
 		userRepo: userRepo,
 		prRepo:   prRepo,
 		prSvc:    prSvc,
+		tx:       tx,
+		metrics:  metrics,
+		access:   access,
 	}
 }
 
-func (s *TeamService) Create(ctx context.Context, team model.Team) (*model.Team, error) {
+func (s *TeamService) Create(ctx context.Context, team model.Team) (_ *model.Team, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.Create", attribute.String("team.name", team.Name))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateTeam(team); err != nil {
 		return nil, fmt.Errorf("team service: %w", err)
 	}
@@ -66,7 +88,165 @@ func (s *TeamService) Get(ctx context.Context, teamName string) (*model.Team, er
 	return team, nil
 }
 
-func (s *TeamService) DeactivateMembers(ctx context.Context, teamName string, userIDs []string) (*model.TeamDeactivationResult, error) {
+func (s *TeamService) UpdateSettings(ctx context.Context, settings model.TeamSettings) (*model.TeamSettings, error) {
+	if err := validateSettings(settings); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	if err := s.access.ManageTeam(ctx, settings.TeamName); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.teamRepo.UpdateSettings(ctx, settings)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return updated, nil
+}
+
+func (s *TeamService) SetReviewSLA(ctx context.Context, teamName string, minutes int) (*model.TeamSettings, error) {
+	if err := validateName(teamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	if minutes < 0 {
+		return nil, fmt.Errorf("team service: review_sla_minutes must not be negative")
+	}
+
+	if err := s.access.ManageTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teamRepo.SetReviewSLA(ctx, teamName, minutes)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *TeamService) SetMergePolicy(ctx context.Context, teamName string, policy model.MergePolicy) (*model.TeamSettings, error) {
+	if err := validateName(teamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	if policy.MinApprovals < 0 {
+		return nil, fmt.Errorf("team service: min_approvals must not be negative")
+	}
+
+	if err := s.access.ManageTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teamRepo.SetMergePolicy(ctx, teamName, policy)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (s *TeamService) SetBackupTeams(ctx context.Context, teamName string, backupTeams []string) (*model.TeamSettings, error) {
+	if err := validateName(teamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	backupTeams = unique(backupTeams)
+	for _, backupTeam := range backupTeams {
+		if backupTeam == teamName {
+			return nil, fmt.Errorf("team service: team cannot be its own backup")
+		}
+	}
+
+	if err := s.access.ManageTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.teamRepo.SetBackupTeams(ctx, teamName, backupTeams)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return settings, nil
+}
+
+// SetRole makes the user an owner or maintainer of the team. Only owners and admins may change roles.
+func (s *TeamService) SetRole(ctx context.Context, assignment model.TeamRoleAssignment) ([]model.TeamRoleAssignment, error) {
+	if err := validateName(assignment.TeamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	if strings.TrimSpace(assignment.UserID) == "" {
+		return nil, fmt.Errorf("team service: user_id is required")
+	}
+
+	if !assignment.Role.Valid() {
+		return nil, fmt.Errorf("team service: invalid team role: %s", assignment.Role)
+	}
+
+	if err := s.access.OwnTeam(ctx, assignment.TeamName); err != nil {
+		return nil, err
+	}
+
+	if err := s.teamRepo.SetRole(ctx, assignment); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return s.ListRoles(ctx, assignment.TeamName)
+}
+
+func (s *TeamService) RemoveRole(ctx context.Context, teamName, userID string) ([]model.TeamRoleAssignment, error) {
+	if err := validateName(teamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	if err := s.access.OwnTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	if err := s.teamRepo.RemoveRole(ctx, teamName, userID); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return s.ListRoles(ctx, teamName)
+}
+
+func (s *TeamService) ListRoles(ctx context.Context, teamName string) ([]model.TeamRoleAssignment, error) {
+	if err := validateName(teamName); err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	exists, err := s.teamRepo.Exists(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+	if !exists {
+		return nil, model.ErrNotFound
+	}
+
+	roles, err := s.teamRepo.ListRoles(ctx, teamName)
+	if err != nil {
+		return nil, fmt.Errorf("team service: %w", err)
+	}
+
+	return roles, nil
+}
+
+// DeactivateMembers reassigns open reviews of the given members and deactivates them in one transaction,
+// so a failure leaves both assignments and users untouched.
+// With AllowPartial a review that has no replacement is dropped, and one that cannot be changed is
+// reported as failed; the members are deactivated anyway. With DryRun the transaction is rolled back
+// and the result only shows the planned changes.
+func (s *TeamService) DeactivateMembers(ctx context.Context, teamName string, userIDs []string, opts model.DeactivationOptions) (_ *model.TeamDeactivationResult, err error) {
+	ctx, span := tracing.Start(ctx, "TeamService.DeactivateMembers",
+		attribute.String("team.name", teamName),
+		attribute.Int("users.count", len(userIDs)),
+		attribute.Bool("allow_partial", opts.AllowPartial),
+		attribute.Bool("dry_run", opts.DryRun),
+	)
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateName(teamName); err != nil {
 		return nil, fmt.Errorf("team service: %w", err)
 	}
@@ -75,8 +255,40 @@ func (s *TeamService) DeactivateMembers(ctx context.Context, teamName string, us
 		return nil, fmt.Errorf("team service: no user ids provided")
 	}
 
+	if err := s.access.ManageTeam(ctx, teamName); err != nil {
+		return nil, err
+	}
+
+	// reviews of the members may sit on other teams' pull requests, they are reassigned on this team's behalf
+	ctx = service.Authorized(ctx)
 	targets := unique(userIDs)
+	ctx = model.WithEventReason(ctx, "team member deactivation")
+
+	var result *model.TeamDeactivationResult
+	deactivate := func(ctx context.Context) error {
+		var err error
+		result, err = s.deactivateMembers(ctx, teamName, targets, opts.AllowPartial)
+		return err
+	}
+
+	if opts.DryRun {
+		err = service.DryRun(ctx, s.tx, deactivate)
+	} else {
+		err = s.tx.WithinTx(ctx, deactivate)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result.DryRun = opts.DryRun
+	if !opts.DryRun {
+		s.metrics.UsersDeactivated(len(result.DeactivatedUserID))
+	}
+
+	return result, nil
+}
 
+func (s *TeamService) deactivateMembers(ctx context.Context, teamName string, targets []string, allowPartial bool) (*model.TeamDeactivationResult, error) {
 	users, err := s.userRepo.ListByIDs(ctx, teamName, targets)
 	if err != nil {
 		return nil, fmt.Errorf("team service: %w", err)
@@ -102,10 +314,39 @@ func (s *TeamService) DeactivateMembers(ctx context.Context, teamName string, us
 		return nil, fmt.Errorf("team service: %w", err)
 	}
 
+	result := &model.TeamDeactivationResult{
+		TeamName: teamName,
+		Slots:    make([]model.ReviewerSlotOutcome, 0, len(assignments)),
+	}
+
 	for _, assignment := range assignments {
-		if _, _, err := s.prSvc.Reassign(ctx, assignment.PullRequestID, assignment.ReviewerID); err != nil {
-			return nil, fmt.Errorf("team service: %w", err)
+		slot := model.ReviewerSlotOutcome{
+			PullRequestID: assignment.PullRequestID,
+			OldReviewerID: assignment.ReviewerID,
+		}
+
+		if !allowPartial {
+			_, replacement, err := s.prSvc.Reassign(ctx, assignment.PullRequestID, assignment.ReviewerID)
+			if err != nil {
+				return nil, fmt.Errorf("team service: %w", err)
+			}
+
+			slot.Outcome = model.SlotReassigned
+			slot.ReplacedBy = replacement
+		} else {
+			slot = s.resolveSlot(ctx, slot)
+		}
+
+		switch slot.Outcome {
+		case model.SlotReassigned:
+			result.ReassignedCount++
+		case model.SlotDropped:
+			result.DroppedCount++
+		case model.SlotFailed:
+			result.FailedCount++
 		}
+
+		result.Slots = append(result.Slots, slot)
 	}
 
 	deactivated, err := s.userRepo.DeactivateUsers(ctx, teamName, activeIDs)
@@ -117,11 +358,42 @@ func (s *TeamService) DeactivateMembers(ctx context.Context, teamName string, us
 		return nil, fmt.Errorf("team service: no users were deactivated")
 	}
 
-	return &model.TeamDeactivationResult{
-		TeamName:          teamName,
-		DeactivatedUserID: deactivated,
-		ReassignedCount:   len(assignments),
-	}, nil
+	result.DeactivatedUserID = deactivated
+
+	return result, nil
+}
+
+// resolveSlot reassigns a review, or drops it when the team has no candidate left.
+// Each attempt runs in its own savepoint so a failure does not abort the whole deactivation.
+func (s *TeamService) resolveSlot(ctx context.Context, slot model.ReviewerSlotOutcome) model.ReviewerSlotOutcome {
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, replacement, err := s.prSvc.Reassign(ctx, slot.PullRequestID, slot.OldReviewerID)
+		slot.ReplacedBy = replacement
+		return err
+	})
+	if err == nil {
+		slot.Outcome = model.SlotReassigned
+		return slot
+	}
+
+	if !errors.Is(err, model.ErrNoCandidate) {
+		slot.Outcome = model.SlotFailed
+		slot.Error = err.Error()
+		return slot
+	}
+
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		_, err := s.prSvc.DropReviewer(ctx, slot.PullRequestID, slot.OldReviewerID)
+		return err
+	})
+	if err != nil {
+		slot.Outcome = model.SlotFailed
+		slot.Error = err.Error()
+		return slot
+	}
+
+	slot.Outcome = model.SlotDropped
+	return slot
 }
 
 func validateTeam(team model.Team) error {
@@ -146,6 +418,22 @@ func validateName(teamName string) error {
 	return nil
 }
 
+func validateSettings(settings model.TeamSettings) error {
+	if err := validateName(settings.TeamName); err != nil {
+		return err
+	}
+
+	if settings.MinReviewers < 0 {
+		return fmt.Errorf("min_reviewers must not be negative")
+	}
+
+	if settings.MaxReviewers < settings.MinReviewers {
+		return fmt.Errorf("max_reviewers must not be less than min_reviewers")
+	}
+
+	return nil
+}
+
 func validateTeamMember(member model.TeamMember) error {
 	if strings.TrimSpace(member.ID) == "" {
 		return fmt.Errorf("member.user_id is required")
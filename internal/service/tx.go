@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+)
+
+var errDryRun = errors.New("dry run")
+
+type dryRunKey struct{}
+
+// IsDryRun reports whether ctx belongs to a DryRun, whose changes are going to be rolled back.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// DryRun runs fn in a transaction that is always rolled back, so a preview goes through
+// the same code path as the real change without persisting anything.
+func DryRun(ctx context.Context, tx Transactor, fn func(ctx context.Context) error) error {
+	ctx = context.WithValue(ctx, dryRunKey{}, true)
+
+	err := tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := fn(ctx); err != nil {
+			return err
+		}
+
+		return errDryRun
+	})
+	if errors.Is(err, errDryRun) {
+		return nil
+	}
+
+	return err
+}
+
+type txHooksKey struct{}
+
+// txHooks collects callbacks for one level of WithinTx; nested levels are savepoints of the outer one.
+type txHooks struct {
+	afterCommit []func()
+	onRollback  []func()
+}
+
+type hookedTransactor struct {
+	tx Transactor
+}
+
+// WithTxHooks wraps tx so that code running inside it can react to the outcome of the transaction
+// through AfterCommit and OnRollback.
+func WithTxHooks(tx Transactor) Transactor {
+	return hookedTransactor{tx: tx}
+}
+
+func (t hookedTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	parent, nested := ctx.Value(txHooksKey{}).(*txHooks)
+	hooks := &txHooks{}
+
+	err := t.tx.WithinTx(context.WithValue(ctx, txHooksKey{}, hooks), fn)
+	if err != nil {
+		for i := len(hooks.onRollback) - 1; i >= 0; i-- {
+			hooks.onRollback[i]()
+		}
+		return err
+	}
+
+	// a released savepoint is only final once the outer transaction commits
+	if nested {
+		parent.afterCommit = append(parent.afterCommit, hooks.afterCommit...)
+		parent.onRollback = append(parent.onRollback, hooks.onRollback...)
+		return nil
+	}
+
+	for _, hook := range hooks.afterCommit {
+		hook()
+	}
+
+	return nil
+}
+
+// AfterCommit runs fn once the outermost transaction of ctx commits, and never if it rolls back.
+// Without a transaction fn runs right away.
+func AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(txHooksKey{}).(*txHooks); ok {
+		hooks.afterCommit = append(hooks.afterCommit, fn)
+		return
+	}
+
+	fn()
+}
+
+// OnRollback runs fn if the transaction of ctx, or any transaction it is nested in, rolls back.
+// Hooks run in reverse order of registration, so state can be restored step by step.
+func OnRollback(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(txHooksKey{}).(*txHooks); ok {
+		hooks.onRollback = append(hooks.onRollback, fn)
+	}
+}
@@ -0,0 +1,86 @@
+package user
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+type absenceReassigner interface {
+	ReassignAbsentReviews(ctx context.Context) (int, error)
+}
+
+// AbsenceWatcher periodically hands over open reviews of users whose absence has just started.
+type AbsenceWatcher struct {
+	service  absenceReassigner
+	logger   *slog.Logger
+	interval time.Duration
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewAbsenceWatcher(service absenceReassigner, logger *slog.Logger, interval time.Duration) *AbsenceWatcher {
+	return &AbsenceWatcher{
+		service:  service,
+		logger:   logger,
+		interval: interval,
+	}
+}
+
+func (w *AbsenceWatcher) Start(ctx context.Context) {
+	ctx, w.cancel = context.WithCancel(ctx)
+
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			if err := w.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				w.logger.Error("absence reassignment failed", "err", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (w *AbsenceWatcher) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+
+	w.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		w.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce hands over open reviews of absences that have started since the last run. The scheduler
+// calls it directly so that only one replica processes absences.
+func (w *AbsenceWatcher) RunOnce(ctx context.Context) error {
+	reassigned, err := w.service.ReassignAbsentReviews(ctx)
+	if reassigned > 0 {
+		w.logger.Info("reassigned reviews of absent users", "count", reassigned)
+	}
+
+	return err
+}
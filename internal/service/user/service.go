@@ -2,35 +2,81 @@ package user
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 
 	"mor80/service-reviewer/internal/model"
 	"mor80/service-reviewer/internal/service"
+	"mor80/service-reviewer/internal/tracing"
 )
 
+type pullRequestService interface {
+	Reassign(ctx context.Context, prID, oldReviewerID string) (*model.PullRequest, string, error)
+}
+
 type UserService struct {
-	userRepo service.UserRepository
-	prRepo   service.PullRequestRepository
+	userRepo    service.UserRepository
+	prRepo      service.PullRequestRepository
+	eventRepo   service.EventRepository
+	absenceRepo service.AbsenceRepository
+	prSvc       pullRequestService
+	tx          service.Transactor
+	metrics     service.DomainMetrics
+	access      service.AccessChecker
 }
 
-func New(userRepo service.UserRepository, prRepo service.PullRequestRepository) *UserService {
+func New(
+	userRepo service.UserRepository,
+	prRepo service.PullRequestRepository,
+	eventRepo service.EventRepository,
+	absenceRepo service.AbsenceRepository,
+	prSvc pullRequestService,
+	tx service.Transactor,
+	metrics service.DomainMetrics,
+	access service.AccessChecker,
+) *UserService {
 	return &UserService{
-		userRepo: userRepo,
-		prRepo:   prRepo,
+		userRepo:    userRepo,
+		prRepo:      prRepo,
+		eventRepo:   eventRepo,
+		absenceRepo: absenceRepo,
+		prSvc:       prSvc,
+		tx:          tx,
+		metrics:     metrics,
+		access:      access,
 	}
 }
 
-func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (*model.User, error) {
+func (s *UserService) SetIsActive(ctx context.Context, userID string, isActive bool) (_ *model.User, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.SetIsActive", attribute.String("user.id", userID), attribute.Bool("user.is_active", isActive))
+	defer func() { tracing.End(span, err) }()
+
 	if err := validateUserID(userID); err != nil {
 		return nil, fmt.Errorf("user service: %w", err)
 	}
 
+	current, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	if err := s.access.ManageTeam(ctx, current.TeamName); err != nil {
+		return nil, err
+	}
+
 	user, err := s.userRepo.SetIsActive(ctx, userID, isActive)
 	if err != nil {
 		return nil, fmt.Errorf("user service: %w", err)
 	}
 
+	if !isActive {
+		s.metrics.UsersDeactivated(1)
+	}
+
 	return user, nil
 }
 
@@ -51,6 +97,205 @@ func (s *UserService) GetReview(ctx context.Context, userID string) ([]model.Pul
 	return prs, nil
 }
 
+func (s *UserService) History(ctx context.Context, userID string) ([]model.Event, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	events, err := s.eventRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	return events, nil
+}
+
+// AddAbsence records a period when the user is not picked as a reviewer. If the absence has already
+// started and asks for auto reassignment, the user's open reviews are handed over right away.
+func (s *UserService) AddAbsence(ctx context.Context, absence model.Absence) (_ *model.Absence, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.AddAbsence", attribute.String("user.id", absence.UserID))
+	defer func() { tracing.End(span, err) }()
+
+	if err := validateAbsence(absence); err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	if err := s.authorizeAbsence(ctx, absence.UserID); err != nil {
+		return nil, err
+	}
+
+	absence.Reason = strings.TrimSpace(absence.Reason)
+
+	created, err := s.absenceRepo.Create(ctx, absence)
+	if err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	if created.AutoReassign && created.ActiveAt(time.Now()) {
+		if _, err := s.reassignAbsence(ctx, created); err != nil {
+			return nil, fmt.Errorf("user service: %w", err)
+		}
+	}
+
+	return created, nil
+}
+
+func (s *UserService) ListAbsences(ctx context.Context, userID string) ([]model.Absence, error) {
+	if err := validateUserID(userID); err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	absences, err := s.absenceRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("user service: %w", err)
+	}
+
+	return absences, nil
+}
+
+func (s *UserService) DeleteAbsence(ctx context.Context, absenceID int64) error {
+	if absenceID <= 0 {
+		return fmt.Errorf("user service: absence_id is required")
+	}
+
+	absence, err := s.absenceRepo.GetByID(ctx, absenceID)
+	if err != nil {
+		return fmt.Errorf("user service: %w", err)
+	}
+
+	if err := s.authorizeAbsence(ctx, absence.UserID); err != nil {
+		return err
+	}
+
+	if err := s.absenceRepo.Delete(ctx, absenceID); err != nil {
+		return fmt.Errorf("user service: %w", err)
+	}
+
+	return nil
+}
+
+// ReassignAbsentReviews hands over open reviews of users whose auto reassigning absence has started
+// and returns how many reviews got a new reviewer.
+func (s *UserService) ReassignAbsentReviews(ctx context.Context) (_ int, err error) {
+	ctx, span := tracing.Start(ctx, "UserService.ReassignAbsentReviews")
+	defer func() { tracing.End(span, err) }()
+
+	// the job acts for nobody in particular: an absence that has started is reason enough to hand
+	// the reviews over, whichever teams the pull requests belong to
+	ctx = service.Authorized(ctx)
+
+	absences, err := s.absenceRepo.ListDueReassignments(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("user service: %w", err)
+	}
+
+	total := 0
+	for i := range absences {
+		reassigned, err := s.reassignAbsence(ctx, &absences[i])
+		if err != nil {
+			return total, fmt.Errorf("user service: %w", err)
+		}
+
+		total += reassigned
+	}
+
+	return total, nil
+}
+
+// reassignAbsence moves every open review of the absent user to another reviewer; each attempt runs
+// in its own savepoint. Reviews that could not be moved, for lack of a candidate or because the caller
+// may not reassign them, stay in place and keep the absence unmarked, so the background job retries
+// them until the absence ends.
+func (s *UserService) reassignAbsence(ctx context.Context, absence *model.Absence) (int, error) {
+	ctx = model.WithEventReason(ctx, "reviewer absence")
+	reassigned := 0
+	pending := false
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		assignments, err := s.prRepo.ListOpenAssignmentsByReviewers(ctx, []string{absence.UserID})
+		if err != nil {
+			return err
+		}
+
+		for _, assignment := range assignments {
+			err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+				_, _, err := s.prSvc.Reassign(ctx, assignment.PullRequestID, assignment.ReviewerID)
+				return err
+			})
+
+			var domainErr model.DomainError
+			switch {
+			case err == nil:
+				reassigned++
+			case errors.As(err, &domainErr):
+				// a pull request merged or a review withdrawn meanwhile leaves nothing to retry
+				switch domainErr.Code {
+				case model.ErrorCodePRMerged, model.ErrorCodePRNotOpen, model.ErrorCodeNotAssigned:
+				default:
+					pending = true
+				}
+			default:
+				return err
+			}
+		}
+
+		if pending {
+			return nil
+		}
+
+		now := time.Now().UTC()
+		if err := s.absenceRepo.MarkReassigned(ctx, absence.ID, now); err != nil {
+			return err
+		}
+
+		absence.ReassignedAt = &now
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return reassigned, nil
+}
+
+// authorizeAbsence lets users manage their own absences; anyone else has to manage the user's team.
+func (s *UserService) authorizeAbsence(ctx context.Context, userID string) error {
+	if principal, ok := model.PrincipalFrom(ctx); ok && principal.UserID != "" && principal.UserID == userID {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("user service: %w", err)
+	}
+
+	return s.access.ManageTeam(ctx, user.TeamName)
+}
+
+func validateAbsence(absence model.Absence) error {
+	if err := validateUserID(absence.UserID); err != nil {
+		return err
+	}
+
+	if absence.StartsAt.IsZero() || absence.EndsAt.IsZero() {
+		return fmt.Errorf("starts_at and ends_at are required")
+	}
+
+	if !absence.EndsAt.After(absence.StartsAt) {
+		return fmt.Errorf("ends_at must be after starts_at")
+	}
+
+	return nil
+}
+
 func validateUserID(userID string) error {
 	if strings.TrimSpace(userID) == "" {
 		return fmt.Errorf("user_id is required")
@@ -0,0 +1,208 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+const (
+	HeaderEvent     = "X-Reviewer-Event"
+	HeaderDelivery  = "X-Reviewer-Delivery"
+	HeaderSignature = "X-Reviewer-Signature"
+)
+
+type DispatcherConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	Timeout      time.Duration
+}
+
+// Dispatcher sends queued webhook deliveries, retrying failures with exponential backoff.
+type Dispatcher struct {
+	repo   service.WebhookRepository
+	client *http.Client
+	logger *slog.Logger
+	config DispatcherConfig
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewDispatcher(repo service.WebhookRepository, client *http.Client, logger *slog.Logger, cfg DispatcherConfig) *Dispatcher {
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+
+	return &Dispatcher{
+		repo:   repo,
+		client: client,
+		logger: logger,
+		config: cfg,
+	}
+}
+
+func (d *Dispatcher) Start(ctx context.Context) {
+	ctx, d.cancel = context.WithCancel(ctx)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.config.PollInterval)
+		defer ticker.Stop()
+
+		for {
+			if err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+				d.logger.Error("webhook dispatch failed", "err", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (d *Dispatcher) Stop(ctx context.Context) error {
+	if d.cancel == nil {
+		return nil
+	}
+
+	d.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunOnce sends up to BatchSize due deliveries. They are claimed one at a time, so the lease
+// only has to cover a single attempt and a slow receiver cannot make later ones go out twice.
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	lease := d.config.Timeout + d.config.PollInterval
+
+	for range d.config.BatchSize {
+		deliveries, err := d.repo.ClaimDue(ctx, 1, lease)
+		if err != nil {
+			return fmt.Errorf("webhook dispatcher: %w", err)
+		}
+
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		if err := d.deliver(ctx, deliveries[0]); err != nil {
+			return fmt.Errorf("webhook dispatcher: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery model.WebhookDelivery) error {
+	attempts := delivery.Attempts + 1
+
+	sendErr := d.send(ctx, delivery)
+	if sendErr == nil {
+		return d.repo.MarkDelivered(ctx, delivery.ID, attempts, time.Now().UTC())
+	}
+
+	d.logger.Warn("webhook delivery attempt failed",
+		"delivery_id", delivery.ID,
+		"webhook_id", delivery.Webhook.ID,
+		"attempt", attempts,
+		"err", sendErr,
+	)
+
+	if attempts >= d.config.MaxAttempts {
+		return d.repo.MarkFailed(ctx, delivery.ID, attempts, sendErr.Error())
+	}
+
+	next := time.Now().UTC().Add(d.backoff(attempts))
+	return d.repo.MarkRetry(ctx, delivery.ID, attempts, next, sendErr.Error())
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery model.WebhookDelivery) error {
+	body, err := json.Marshal(delivery.Event)
+	if err != nil {
+		return err
+	}
+
+	return Post(ctx, d.client, delivery.Webhook.URL, delivery.Webhook.Secret, delivery.Event.Type, delivery.ID, body)
+}
+
+// Post sends body to url with the event, delivery and signature headers receivers verify.
+// Any status outside 2xx is an error, so the caller can retry.
+func Post(ctx context.Context, client *http.Client, url, secret string, eventType model.EventType, deliveryID int64, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, string(eventType))
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(deliveryID, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.config.BaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= d.config.MaxBackoff {
+			return d.config.MaxBackoff
+		}
+	}
+
+	return delay
+}
+
+// Sign returns the signature header value receivers compare against
+// an HMAC-SHA256 of the raw request body keyed with the webhook secret.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,190 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mor80/service-reviewer/internal/model"
+)
+
+type deliveryResult struct {
+	id            int64
+	attempts      int
+	status        model.WebhookDeliveryStatus
+	nextAttemptAt time.Time
+	lastError     string
+}
+
+// fakeRepo hands out queued deliveries and records what the dispatcher did with them.
+type fakeRepo struct {
+	mu      sync.Mutex
+	queue   []model.WebhookDelivery
+	claims  []int
+	results []deliveryResult
+}
+
+func (r *fakeRepo) Create(context.Context, model.Webhook) (*model.Webhook, error) { return nil, nil }
+func (r *fakeRepo) List(context.Context) ([]model.Webhook, error)                 { return nil, nil }
+func (r *fakeRepo) Delete(context.Context, int64) error                           { return nil }
+
+func (r *fakeRepo) ClaimDue(_ context.Context, limit int, _ time.Duration) ([]model.WebhookDelivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.claims = append(r.claims, limit)
+	n := min(limit, len(r.queue))
+	claimed := r.queue[:n]
+	r.queue = r.queue[n:]
+
+	return claimed, nil
+}
+
+func (r *fakeRepo) MarkDelivered(_ context.Context, id int64, attempts int, _ time.Time) error {
+	return r.record(deliveryResult{id: id, attempts: attempts, status: model.WebhookDeliveryDelivered})
+}
+
+func (r *fakeRepo) MarkRetry(_ context.Context, id int64, attempts int, next time.Time, lastError string) error {
+	return r.record(deliveryResult{id: id, attempts: attempts, status: model.WebhookDeliveryPending, nextAttemptAt: next, lastError: lastError})
+}
+
+func (r *fakeRepo) MarkFailed(_ context.Context, id int64, attempts int, lastError string) error {
+	return r.record(deliveryResult{id: id, attempts: attempts, status: model.WebhookDeliveryFailed, lastError: lastError})
+}
+
+func (r *fakeRepo) record(result deliveryResult) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.results = append(r.results, result)
+	return nil
+}
+
+func newTestDispatcher(repo *fakeRepo, cfg DispatcherConfig) *Dispatcher {
+	return NewDispatcher(repo, nil, slog.New(slog.NewTextHandler(io.Discard, nil)), cfg)
+}
+
+func testDelivery(id int64, url string, attempts int) model.WebhookDelivery {
+	return model.WebhookDelivery{
+		ID:      id,
+		Webhook: model.Webhook{ID: 1, URL: url, Secret: "s3cret"},
+		Event: model.Event{
+			ID:            id,
+			Type:          model.EventPullRequestCreated,
+			PullRequestID: "pr-1",
+		},
+		Attempts: attempts,
+	}
+}
+
+func TestDispatcherSignsDeliveries(t *testing.T) {
+	type received struct {
+		body    []byte
+		headers http.Header
+	}
+	got := make(chan received, 2)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		got <- received{body: body, headers: r.Header.Clone()}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	repo := &fakeRepo{queue: []model.WebhookDelivery{testDelivery(7, srv.URL, 0), testDelivery(8, srv.URL, 0)}}
+	d := newTestDispatcher(repo, DispatcherConfig{BatchSize: 10, MaxAttempts: 3, BaseBackoff: time.Second, MaxBackoff: time.Minute, Timeout: time.Second})
+
+	if err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	for _, id := range []int64{7, 8} {
+		req := <-got
+
+		if sig := req.headers.Get(HeaderSignature); !hmac.Equal([]byte(sig), []byte(Sign("s3cret", req.body))) {
+			t.Errorf("delivery %d: signature %q does not match body", id, sig)
+		}
+		if h := req.headers.Get(HeaderDelivery); h != strconv.FormatInt(id, 10) {
+			t.Errorf("delivery header = %q, want %d", h, id)
+		}
+		if h := req.headers.Get(HeaderEvent); h != "PR_CREATED" {
+			t.Errorf("event header = %q, want PR_CREATED", h)
+		}
+
+		var event model.Event
+		if err := json.Unmarshal(req.body, &event); err != nil || event.ID != id {
+			t.Errorf("body = %s, want event %d", req.body, id)
+		}
+	}
+
+	if len(repo.results) != 2 {
+		t.Fatalf("results = %+v, want 2 delivered", repo.results)
+	}
+	for _, res := range repo.results {
+		if res.status != model.WebhookDeliveryDelivered || res.attempts != 1 {
+			t.Errorf("result = %+v, want delivered on attempt 1", res)
+		}
+	}
+
+	// deliveries are claimed one by one so each lease covers a single attempt
+	for _, limit := range repo.claims {
+		if limit != 1 {
+			t.Errorf("claimed %d deliveries at once, want 1", limit)
+		}
+	}
+}
+
+func TestDispatcherRetriesServerErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	cfg := DispatcherConfig{BatchSize: 10, MaxAttempts: 3, BaseBackoff: 10 * time.Second, MaxBackoff: time.Minute, Timeout: time.Second}
+	repo := &fakeRepo{queue: []model.WebhookDelivery{testDelivery(1, srv.URL, 0), testDelivery(2, srv.URL, 1), testDelivery(3, srv.URL, 2)}}
+	d := newTestDispatcher(repo, cfg)
+
+	started := time.Now().UTC()
+	if err := d.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce: %v", err)
+	}
+
+	if len(repo.results) != 3 {
+		t.Fatalf("results = %+v, want 3", repo.results)
+	}
+
+	for i, wantBackoff := range []time.Duration{10 * time.Second, 20 * time.Second} {
+		res := repo.results[i]
+		if res.status != model.WebhookDeliveryPending || res.attempts != i+1 {
+			t.Errorf("result = %+v, want retry after attempt %d", res, i+1)
+		}
+		if delay := res.nextAttemptAt.Sub(started); delay < wantBackoff || delay > wantBackoff+5*time.Second {
+			t.Errorf("delivery %d retried in %v, want about %v", res.id, delay, wantBackoff)
+		}
+		if res.lastError == "" {
+			t.Errorf("delivery %d: last error not recorded", res.id)
+		}
+	}
+
+	if res := repo.results[2]; res.status != model.WebhookDeliveryFailed || res.attempts != 3 {
+		t.Errorf("result = %+v, want failed after the last attempt", res)
+	}
+}
+
+func TestDispatcherBackoffIsCapped(t *testing.T) {
+	d := newTestDispatcher(&fakeRepo{}, DispatcherConfig{BaseBackoff: 5 * time.Second, MaxBackoff: time.Minute})
+
+	for attempts, want := range map[int]time.Duration{1: 5 * time.Second, 2: 10 * time.Second, 4: 40 * time.Second, 5: time.Minute, 10: time.Minute} {
+		if got := d.backoff(attempts); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", attempts, got, want)
+		}
+	}
+}
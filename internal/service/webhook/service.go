@@ -0,0 +1,101 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"mor80/service-reviewer/internal/model"
+	"mor80/service-reviewer/internal/service"
+)
+
+const secretBytes = 32
+
+type WebhookService struct {
+	repo service.WebhookRepository
+}
+
+func New(repo service.WebhookRepository) *WebhookService {
+	return &WebhookService{repo: repo}
+}
+
+// Register stores a webhook. The secret is generated when empty and returned only here.
+func (s *WebhookService) Register(ctx context.Context, webhook model.Webhook) (*model.Webhook, error) {
+	if err := validateWebhook(webhook); err != nil {
+		return nil, fmt.Errorf("webhook service: %w", err)
+	}
+
+	if webhook.Secret == "" {
+		secret, err := generateSecret()
+		if err != nil {
+			return nil, fmt.Errorf("webhook service: %w", err)
+		}
+
+		webhook.Secret = secret
+	}
+
+	webhook.IsActive = true
+
+	created, err := s.repo.Create(ctx, webhook)
+	if err != nil {
+		return nil, fmt.Errorf("webhook service: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *WebhookService) List(ctx context.Context) ([]model.Webhook, error) {
+	webhooks, err := s.repo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("webhook service: %w", err)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (s *WebhookService) Delete(ctx context.Context, webhookID int64) error {
+	if err := s.repo.Delete(ctx, webhookID); err != nil {
+		return fmt.Errorf("webhook service: %w", err)
+	}
+
+	return nil
+}
+
+func validateWebhook(webhook model.Webhook) error {
+	if strings.TrimSpace(webhook.URL) == "" {
+		return fmt.Errorf("url is required")
+	}
+
+	parsed, err := url.Parse(webhook.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("url must be an absolute http or https url")
+	}
+
+	if len(webhook.EventTypes) == 0 {
+		return fmt.Errorf("event_types is required")
+	}
+
+	for _, eventType := range webhook.EventTypes {
+		if !eventType.Valid() {
+			return fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+
+	return nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, secretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
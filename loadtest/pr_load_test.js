@@ -15,6 +15,7 @@ export const options = {
 };
 
 const BASE_URL = 'http://localhost:8080';
+const TOKEN = __ENV.TOKEN || '';
 
 export default function () {
     const prId = `pr-${__VU}-${__ITER}`;
@@ -27,7 +28,10 @@ export default function () {
             author_id: 'u1',
         }),
         {
-            headers: { 'Content-Type': 'application/json' },
+            headers: {
+                'Content-Type': 'application/json',
+                Authorization: `Bearer ${TOKEN}`,
+            },
         }
     );
 
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams
+    ADD COLUMN min_reviewers INT NOT NULL DEFAULT 1,
+    ADD COLUMN max_reviewers INT NOT NULL DEFAULT 2,
+    ADD CONSTRAINT chk_teams_reviewers
+        CHECK (min_reviewers >= 0 AND max_reviewers >= min_reviewers);
+
+ALTER TABLE pull_requests
+    ADD COLUMN needs_reviewers BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS needs_reviewers;
+
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS chk_teams_reviewers,
+    DROP COLUMN IF EXISTS max_reviewers,
+    DROP COLUMN IF EXISTS min_reviewers;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE team_backup_teams (
+    team_name        VARCHAR(255) NOT NULL,
+    backup_team_name VARCHAR(255) NOT NULL,
+    position         INT          NOT NULL,
+    CONSTRAINT fk_tbt_team
+        FOREIGN KEY (team_name)
+        REFERENCES teams(team_name)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+    CONSTRAINT fk_tbt_backup_team
+        FOREIGN KEY (backup_team_name)
+        REFERENCES teams(team_name)
+        ON UPDATE CASCADE
+        ON DELETE CASCADE,
+    CONSTRAINT chk_tbt_not_self
+        CHECK (team_name <> backup_team_name),
+    PRIMARY KEY (team_name, backup_team_name)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS team_backup_teams;
+-- +goose StatementEnd
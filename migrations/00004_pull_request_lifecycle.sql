@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    DROP CONSTRAINT chk_pr_status,
+    ADD CONSTRAINT chk_pr_status
+        CHECK (status IN ('DRAFT', 'OPEN', 'MERGED', 'CLOSED'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+UPDATE pull_requests SET status = 'OPEN' WHERE status IN ('DRAFT', 'CLOSED');
+
+ALTER TABLE pull_requests
+    DROP CONSTRAINT chk_pr_status,
+    ADD CONSTRAINT chk_pr_status
+        CHECK (status IN ('OPEN', 'MERGED'));
+-- +goose StatementEnd
@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN review_state VARCHAR(32) NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN reviewed_at  TIMESTAMPTZ NULL,
+    ADD CONSTRAINT chk_prr_review_state
+        CHECK (review_state IN ('PENDING', 'APPROVED', 'CHANGES_REQUESTED', 'COMMENTED'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request_reviewers
+    DROP CONSTRAINT IF EXISTS chk_prr_review_state,
+    DROP COLUMN IF EXISTS reviewed_at,
+    DROP COLUMN IF EXISTS review_state;
+-- +goose StatementEnd
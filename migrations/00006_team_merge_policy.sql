@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams
+    ADD COLUMN merge_min_approvals         INT     NOT NULL DEFAULT 0,
+    ADD COLUMN merge_require_reviewer      BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN merge_require_active_author BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT chk_teams_merge_min_approvals
+        CHECK (merge_min_approvals >= 0);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS chk_teams_merge_min_approvals,
+    DROP COLUMN IF EXISTS merge_require_active_author,
+    DROP COLUMN IF EXISTS merge_require_reviewer,
+    DROP COLUMN IF EXISTS merge_min_approvals;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE events (
+    event_id        BIGSERIAL    PRIMARY KEY,
+    event_type      VARCHAR(64)  NOT NULL,
+    pull_request_id VARCHAR(255) NULL,
+    user_id         VARCHAR(255) NULL,
+    payload         JSONB        NOT NULL DEFAULT '{}',
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_events_pull_request_id ON events(pull_request_id);
+CREATE INDEX idx_events_user_id         ON events(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_events_user_id;
+DROP INDEX IF EXISTS idx_events_pull_request_id;
+DROP TABLE IF EXISTS events;
+-- +goose StatementEnd
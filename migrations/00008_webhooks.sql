@@ -0,0 +1,43 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE webhooks (
+    webhook_id  BIGSERIAL   PRIMARY KEY,
+    url         TEXT        NOT NULL,
+    secret      TEXT        NOT NULL,
+    event_types TEXT[]      NOT NULL,
+    is_active   BOOLEAN     NOT NULL DEFAULT TRUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE webhook_deliveries (
+    delivery_id     BIGSERIAL   PRIMARY KEY,
+    webhook_id      BIGINT      NOT NULL,
+    event_id        BIGINT      NOT NULL,
+    status          VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    attempts        INT         NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error      TEXT        NULL,
+    delivered_at    TIMESTAMPTZ NULL,
+    CONSTRAINT fk_wd_webhook
+        FOREIGN KEY (webhook_id)
+        REFERENCES webhooks(webhook_id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_wd_event
+        FOREIGN KEY (event_id)
+        REFERENCES events(event_id)
+        ON DELETE CASCADE,
+    CONSTRAINT chk_wd_status
+        CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED'))
+);
+
+CREATE INDEX idx_wd_pending
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'PENDING';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wd_pending;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+-- +goose StatementEnd
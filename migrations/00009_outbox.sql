@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE outbox (
+    outbox_id    BIGSERIAL   PRIMARY KEY,
+    event_id     BIGINT      NOT NULL,
+    event_type   VARCHAR(64) NOT NULL,
+    payload      JSONB       NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts     INT         NOT NULL DEFAULT 0,
+    locked_until TIMESTAMPTZ NULL,
+    last_error   TEXT        NULL,
+    published_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX idx_outbox_unpublished
+    ON outbox(outbox_id)
+    WHERE published_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_unpublished;
+DROP TABLE IF EXISTS outbox;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE user_absences (
+    absence_id    BIGSERIAL   PRIMARY KEY,
+    user_id       VARCHAR(255) NOT NULL,
+    starts_at     TIMESTAMPTZ NOT NULL,
+    ends_at       TIMESTAMPTZ NOT NULL,
+    reason        TEXT        NOT NULL DEFAULT '',
+    auto_reassign BOOLEAN     NOT NULL DEFAULT FALSE,
+    reassigned_at TIMESTAMPTZ NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_absence_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(user_id)
+        ON DELETE CASCADE,
+    CONSTRAINT chk_absence_period
+        CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_absences_user_period
+    ON user_absences(user_id, starts_at, ends_at);
+
+CREATE INDEX idx_absences_pending_reassign
+    ON user_absences(starts_at)
+    WHERE auto_reassign AND reassigned_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_absences_pending_reassign;
+DROP INDEX IF EXISTS idx_absences_user_period;
+DROP TABLE IF EXISTS user_absences;
+-- +goose StatementEnd
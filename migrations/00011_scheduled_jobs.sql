@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE pull_requests
+    ADD COLUMN escalated_at TIMESTAMPTZ NULL;
+
+CREATE TABLE assignment_stats_snapshots (
+    taken_at    TIMESTAMPTZ  NOT NULL,
+    reviewer_id VARCHAR(255) NOT NULL,
+    total_count INT          NOT NULL,
+    open_count  INT          NOT NULL,
+    PRIMARY KEY (taken_at, reviewer_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS assignment_stats_snapshots;
+
+ALTER TABLE pull_requests
+    DROP COLUMN IF EXISTS escalated_at;
+-- +goose StatementEnd
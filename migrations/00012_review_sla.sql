@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE teams
+    ADD COLUMN review_sla_minutes INT NOT NULL DEFAULT 0,
+    ADD CONSTRAINT chk_teams_review_sla
+        CHECK (review_sla_minutes >= 0);
+
+ALTER TABLE pull_request_reviewers
+    ADD COLUMN assigned_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+UPDATE pull_request_reviewers prr
+SET assigned_at = COALESCE(pr.created_at, NOW())
+FROM pull_requests pr
+WHERE pr.pull_request_id = prr.pull_request_id;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE pull_request_reviewers
+    DROP COLUMN IF EXISTS assigned_at;
+
+ALTER TABLE teams
+    DROP CONSTRAINT IF EXISTS chk_teams_review_sla,
+    DROP COLUMN IF EXISTS review_sla_minutes;
+-- +goose StatementEnd
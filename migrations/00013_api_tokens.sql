@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE api_tokens (
+    token_id     BIGSERIAL    PRIMARY KEY,
+    user_id      VARCHAR(255) NOT NULL,
+    name         TEXT         NOT NULL DEFAULT '',
+    token_hash   CHAR(64)     NOT NULL,
+    role         VARCHAR(16)  NOT NULL,
+    expires_at   TIMESTAMPTZ  NULL,
+    last_used_at TIMESTAMPTZ  NULL,
+    revoked_at   TIMESTAMPTZ  NULL,
+    created_at   TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_api_token_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(user_id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_api_token_hash
+        UNIQUE (token_hash),
+    CONSTRAINT chk_api_token_role
+        CHECK (role IN ('member', 'lead', 'admin'))
+);
+
+CREATE INDEX idx_api_tokens_user
+    ON api_tokens(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_api_tokens_user;
+DROP TABLE IF EXISTS api_tokens;
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE team_roles (
+    team_name  VARCHAR(255) NOT NULL,
+    user_id    VARCHAR(255) NOT NULL,
+    role       VARCHAR(16)  NOT NULL,
+    created_at TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (team_name, user_id),
+    CONSTRAINT fk_team_role_team
+        FOREIGN KEY (team_name)
+        REFERENCES teams(team_name)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_team_role_user
+        FOREIGN KEY (user_id)
+        REFERENCES users(user_id)
+        ON DELETE CASCADE,
+    CONSTRAINT chk_team_role
+        CHECK (role IN ('owner', 'maintainer'))
+);
+
+CREATE INDEX idx_team_roles_user
+    ON team_roles(user_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_team_roles_user;
+DROP TABLE IF EXISTS team_roles;
+-- +goose StatementEnd
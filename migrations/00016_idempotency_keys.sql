@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE idempotency_keys (
+    client_key      VARCHAR(512) NOT NULL,
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash    CHAR(64)     NOT NULL,
+    status_code     INT          NULL,
+    content_type    TEXT         NOT NULL DEFAULT '',
+    response_body   BYTEA        NULL,
+    created_at      TIMESTAMPTZ  NOT NULL DEFAULT NOW(),
+    expires_at      TIMESTAMPTZ  NOT NULL,
+    PRIMARY KEY (client_key, idempotency_key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires
+    ON idempotency_keys(expires_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_idempotency_keys_expires;
+DROP TABLE IF EXISTS idempotency_keys;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE scheduled_job_runs (
+    job_name    VARCHAR(255) PRIMARY KEY,
+    last_run_at TIMESTAMPTZ  NOT NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS scheduled_job_runs;
+-- +goose StatementEnd
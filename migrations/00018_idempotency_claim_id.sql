@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys
+    ADD COLUMN claim_id CHAR(32) NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE idempotency_keys
+    DROP COLUMN IF EXISTS claim_id;
+-- +goose StatementEnd